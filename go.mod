@@ -1,6 +1,6 @@
 module github.com/AskAlexSharov/inblocks_reproduce
 
-go 1.16
+go 1.21
 
 require (
 	github.com/c2h5oh/datasize v0.0.0-20200825124411-48ed595a09d2
@@ -0,0 +1,172 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include <stdio.h>
+#include "mdbxgo.h"
+*/
+import "C"
+import (
+	"unsafe"
+)
+
+// Attributes are 64-bit values stored alongside an item's value, which makes
+// them suitable for record versions or expiration marks that should not be
+// part of the value encoding.
+//
+// The record layout is that of the libmdbx attribute API (mdbx_put_attr and
+// friends): a native-endian uint64 followed by the value data.  The C library
+// only provides that API when built with MDBX_NEXENTA_ATTRS, and the bundled
+// version does not compile in that configuration, so the layout is
+// implemented by the helpers in mdbxgo.c instead and no build tag is needed.
+//
+// Items written with an attribute must be read using GetAttr, otherwise the
+// attribute appears as the first 8 bytes of the value.  Attributes are not
+// supported in DupSort databases.
+
+// PutAttr stores an item with attribute attr in database dbi.
+//
+// See mdbx_put_attr.
+func (txn *Txn) PutAttr(dbi DBI, key []byte, val []byte, attr uint64, flags uint) error {
+	if err := txn.check("mdbx_put_attr"); err != nil {
+		return err
+	}
+	kdata, kn := valBytes(key)
+	vdata, vn := valBytes(val)
+	ret := C.mdbxgo_put_attr(
+		txn._txn, C.MDBX_dbi(dbi),
+		(*C.char)(unsafe.Pointer(&kdata[0])), C.size_t(kn),
+		(*C.char)(unsafe.Pointer(&vdata[0])), C.size_t(vn),
+		C.uint64_t(attr),
+		C.MDBX_put_flags_t(flags),
+	)
+	return operrno("mdbx_put_attr", ret)
+}
+
+// GetAttr retrieves an item and its attribute from database dbi.  If
+// txn.RawRead is true the slice returned by GetAttr references a readonly
+// section of memory that must not be accessed after txn has terminated.
+//
+// GetAttr returns an error with errno Incompatible if the stored value is too
+// short to hold an attribute.
+//
+// See mdbx_get_attr.
+func (txn *Txn) GetAttr(dbi DBI, key []byte) ([]byte, uint64, error) {
+	if err := txn.check("mdbx_get_attr"); err != nil {
+		return nil, 0, err
+	}
+	kdata, kn := valBytes(key)
+	var attr C.uint64_t
+	ret := C.mdbxgo_get_attr(
+		txn._txn, C.MDBX_dbi(dbi),
+		(*C.char)(unsafe.Pointer(&kdata[0])), C.size_t(kn),
+		txn.val, &attr,
+	)
+	err := operrno("mdbx_get_attr", ret)
+	if err != nil {
+		*txn.val = C.MDBX_val{}
+		return nil, 0, err
+	}
+	b := txn.bytes(txn.val)
+	*txn.val = C.MDBX_val{}
+	return b, uint64(attr), nil
+}
+
+// SetAttr changes the attribute of an item in database dbi.  If val is nil
+// the value already stored for key is kept and NotFound is returned when key
+// does not exist.  Otherwise the value is replaced by val, whether or not it
+// was stored with an attribute, or a new item is created if key does not
+// exist.
+//
+// See mdbx_set_attr.
+func (txn *Txn) SetAttr(dbi DBI, key []byte, val []byte, attr uint64) error {
+	if err := txn.check("mdbx_set_attr"); err != nil {
+		return err
+	}
+	kdata, kn := valBytes(key)
+	if val == nil {
+		ret := C.mdbxgo_set_attr(
+			txn._txn, C.MDBX_dbi(dbi),
+			(*C.char)(unsafe.Pointer(&kdata[0])), C.size_t(kn),
+			nil, 0,
+			C.uint64_t(attr),
+		)
+		return operrno("mdbx_set_attr", ret)
+	}
+	vdata, vn := valBytes(val)
+	ret := C.mdbxgo_set_attr(
+		txn._txn, C.MDBX_dbi(dbi),
+		(*C.char)(unsafe.Pointer(&kdata[0])), C.size_t(kn),
+		(*C.char)(unsafe.Pointer(&vdata[0])), C.size_t(vn),
+		C.uint64_t(attr),
+	)
+	return operrno("mdbx_set_attr", ret)
+}
+
+// PutAttr stores an item with attribute attr in the database.
+//
+// See mdbx_cursor_put_attr.
+func (c *Cursor) PutAttr(key, val []byte, attr uint64, flags uint) error {
+	if err := c.txn.check("mdbx_cursor_put_attr"); err != nil {
+		return err
+	}
+	kdata, kn := valBytes(key)
+	vdata, vn := valBytes(val)
+	ret := C.mdbxgo_cursor_put_attr(
+		c._c,
+		(*C.char)(unsafe.Pointer(&kdata[0])), C.size_t(kn),
+		(*C.char)(unsafe.Pointer(&vdata[0])), C.size_t(vn),
+		C.uint64_t(attr),
+		C.MDBX_put_flags_t(flags),
+	)
+	return operrno("mdbx_cursor_put_attr", ret)
+}
+
+// GetAttr retrieves an item and its attribute from the database.  GetAttr
+// behaves like Get regarding setkey and the lifetime of the returned slices.
+// Ops which take a value for reference (GetBoth, etc) are not supported
+// because stored values are prefixed by their attribute.
+//
+// See mdbx_cursor_get_attr.
+func (c *Cursor) GetAttr(setkey []byte, op uint) (key, val []byte, attr uint64, err error) {
+	if err := c.txn.check("mdbx_cursor_get_attr"); err != nil {
+		return nil, nil, 0, err
+	}
+	var _attr C.uint64_t
+	var ret C.int
+	if len(setkey) == 0 {
+		ret = C.mdbxgo_cursor_get_attr(c._c, nil, 0, c.txn.key, c.txn.val, &_attr, C.MDBX_cursor_op(op))
+	} else {
+		ret = C.mdbxgo_cursor_get_attr(
+			c._c,
+			(*C.char)(unsafe.Pointer(&setkey[0])), C.size_t(len(setkey)),
+			c.txn.key, c.txn.val, &_attr,
+			C.MDBX_cursor_op(op),
+		)
+	}
+	err = operrno("mdbx_cursor_get_attr", ret)
+	if err != nil {
+		*c.txn.key = C.MDBX_val{}
+		*c.txn.val = C.MDBX_val{}
+		return nil, nil, 0, err
+	}
+
+	// See Cursor.Get for the handling of keys returned by the Set op.
+	if op == Set {
+		if c.txn.RawRead {
+			key = setkey
+		} else {
+			p := make([]byte, len(setkey))
+			copy(p, setkey)
+			key = p
+		}
+	} else {
+		key = c.txn.bytes(c.txn.key)
+	}
+	val = c.txn.bytes(c.txn.val)
+
+	*c.txn.key = C.MDBX_val{}
+	*c.txn.val = C.MDBX_val{}
+
+	return key, val, uint64(_attr), nil
+}
@@ -0,0 +1,234 @@
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTxn_PutAttr(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBI("attrs", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		err = txn.PutAttr(db, []byte("k0"), []byte("v0"), 7, 0)
+		if err != nil {
+			return err
+		}
+		err = txn.PutAttr(db, []byte("k1"), nil, 8, 0)
+		if err != nil {
+			return err
+		}
+		err = txn.Put(db, []byte("plain"), []byte("v"), 0)
+		if err != nil {
+			return err
+		}
+		err = txn.SetAttr(db, []byte("k2"), nil, 1)
+		if !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		// replace only the attribute, keeping the value.
+		return txn.SetAttr(db, []byte("k0"), nil, 9)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) (err error) {
+		v, attr, err := txn.GetAttr(db, []byte("k0"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v0" {
+			t.Errorf("unexpected value: %q (!= %q)", v, "v0")
+		}
+		if attr != 9 {
+			t.Errorf("unexpected attr: %d (!= %d)", attr, 9)
+		}
+
+		v, attr, err = txn.GetAttr(db, []byte("k1"))
+		if err != nil {
+			return err
+		}
+		if len(v) != 0 {
+			t.Errorf("unexpected value: %q", v)
+		}
+		if attr != 8 {
+			t.Errorf("unexpected attr: %d (!= %d)", attr, 8)
+		}
+
+		_, _, err = txn.GetAttr(db, []byte("k2"))
+		if !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_, _, err = txn.GetAttr(db, []byte("plain"))
+		if !IsErrno(err, Incompatible) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCursor_PutAttr(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBI("attrs", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		for i, k := range []string{"a", "b", "c"} {
+			err = cur.PutAttr([]byte(k), []byte("v"+k), uint64(i+1), Append)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) (err error) {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		var n uint64
+		for {
+			k, v, attr, err := cur.GetAttr(nil, Next)
+			if IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			n++
+			if string(v) != "v"+string(k) {
+				t.Errorf("unexpected value for %q: %q", k, v)
+			}
+			if attr != n {
+				t.Errorf("unexpected attr for %q: %d (!= %d)", k, attr, n)
+			}
+		}
+		if n != 3 {
+			t.Errorf("unexpected number of items: %d", n)
+		}
+
+		k, _, attr, err := cur.GetAttr([]byte("b"), Set)
+		if err != nil {
+			return err
+		}
+		if string(k) != "b" || attr != 2 {
+			t.Errorf("unexpected item: %q %d", k, attr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTxn_SetAttr_short(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenDBI("attrs", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := txn.Put(db, []byte("k"), []byte("short"), 0); err != nil {
+			return err
+		}
+		if err := txn.SetAttr(db, []byte("k"), nil, 1); !IsErrno(err, Incompatible) {
+			t.Errorf("keeping a value without attribute: unexpected error: %v", err)
+		}
+		// a new value replaces the one without attribute.
+		if err := txn.SetAttr(db, []byte("k"), []byte("v"), 2); err != nil {
+			return err
+		}
+		v, attr, err := txn.GetAttr(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v" || attr != 2 {
+			t.Errorf("unexpected item: %q %d", v, attr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_Attr_check(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenDBI("attrs", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		k := []byte("k")
+		return txn.Sub(func(sub *Txn) error {
+			if err := txn.PutAttr(db, k, k, 1, 0); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("PutAttr: unexpected error: %v", err)
+			}
+			if _, _, err := txn.GetAttr(db, k); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("GetAttr: unexpected error: %v", err)
+			}
+			if err := txn.SetAttr(db, k, nil, 1); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("SetAttr: unexpected error: %v", err)
+			}
+			if err := cur.PutAttr(k, k, 1, 0); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("Cursor.PutAttr: unexpected error: %v", err)
+			}
+			if _, _, _, err := cur.GetAttr(nil, First); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("Cursor.GetAttr: unexpected error: %v", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = env.ViewCtx(ctx, func(txn *Txn) error {
+		db, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		cancel()
+		_, _, err = txn.GetAttr(db, []byte("k"))
+		return err
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAttr in a cancelled transaction: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package mdbx
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default values of Env.MaxBatchSize and Env.MaxBatchDelay.
+const (
+	DefaultMaxBatchSize  = 1000
+	DefaultMaxBatchDelay = 10 * time.Millisecond
+)
+
+// errBatchFailed tells the caller of a batched operation that the batch it
+// was part of failed and that it has to be run on its own.
+var errBatchFailed = errors.New("batch failed")
+
+// Batch calls fn as part of a batch.  It behaves like Update, except that
+// concurrent Batch calls are combined into a single write transaction and a
+// single commit, which saves a commit (and possibly an fsync) per call when
+// many goroutines perform small writes.
+//
+// The batch is committed once Env.MaxBatchSize calls have joined it or
+// Env.MaxBatchDelay has passed since the first call, whichever comes first.
+// Batch is only useful when it is called from several goroutines, a lone
+// caller just waits for the delay to pass.
+//
+// If any fn in a batch returns an error the batch transaction is aborted and
+// every fn of the batch is run again in its own transaction, so that each
+// caller gets the error of its own fn.  Because of this fn may be called more
+// than once and must not have side effects outside of the transaction, and
+// its error must only depend on its transaction.
+//
+// Unlike Update, Batch may be called from goroutines which are locked to
+// their thread, or whose locking state is unknown, because the transactions
+// are run on a separate goroutine.
+func (env *Env) Batch(fn TxnOp) error {
+	errc := make(chan error, 1)
+
+	env.batchMu.Lock()
+	if env.batch == nil || len(env.batch.calls) >= env.MaxBatchSize {
+		// There is no batch or the current one is full, start a new one.
+		env.batch = &batch{env: env}
+		env.batch.timer = time.AfterFunc(env.MaxBatchDelay, env.batch.trigger)
+	}
+	env.batch.calls = append(env.batch.calls, batchCall{fn: fn, errc: errc})
+	if len(env.batch.calls) >= env.MaxBatchSize {
+		// Wake up the batch, it is ready to run.
+		go env.batch.trigger()
+	}
+	env.batchMu.Unlock()
+
+	return <-errc
+}
+
+type batchCall struct {
+	fn   TxnOp
+	errc chan<- error
+}
+
+type batch struct {
+	env   *Env
+	timer *time.Timer
+	start sync.Once
+	calls []batchCall
+}
+
+// trigger runs the batch if it has not already been run.
+func (b *batch) trigger() {
+	b.start.Do(b.run)
+}
+
+func (b *batch) run() {
+	b.env.batchMu.Lock()
+	b.timer.Stop()
+	// Make sure no new calls are added to this batch.
+	if b.env.batch == b {
+		b.env.batch = nil
+	}
+	b.env.batchMu.Unlock()
+
+	err := b.env.Update(func(txn *Txn) error {
+		for _, c := range b.calls {
+			if err := safelyCall(c.fn, txn); err != nil {
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if err != errBatchFailed {
+		for _, c := range b.calls {
+			c.errc <- err
+		}
+		return
+	}
+
+	for _, c := range b.calls {
+		c.errc <- b.env.Update(func(txn *Txn) error {
+			return safelyCall(c.fn, txn)
+		})
+	}
+}
+
+// safelyCall converts a panic in fn into an error, a panic must not take the
+// whole batch down.
+func safelyCall(fn TxnOp, txn *Txn) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("batch function panicked: %v", p)
+		}
+	}()
+	return fn(txn)
+}
@@ -0,0 +1,134 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnv_Batch(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+	env.MaxBatchSize = 50
+	env.MaxBatchDelay = time.Second
+
+	db, err := openDBI(env, "batch", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info0, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 200
+	var wg sync.WaitGroup
+	errc := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			errc <- env.Batch(func(txn *Txn) error {
+				return txn.Put(db, k[:], k[:], 0)
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	info1, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// full batches are triggered without waiting for the delay, so n calls
+	// are committed in n/MaxBatchSize transactions.
+	if commits := info1.LastTxnID - info0.LastTxnID; commits != n/50 {
+		t.Errorf("unexpected number of commits: %d (!= %d)", commits, n/50)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != n {
+			t.Errorf("unexpected entries: %d (!= %d)", stat.Entries, n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnv_Batch_error(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+	env.MaxBatchSize = 10
+	env.MaxBatchDelay = time.Second
+
+	db, err := openDBI(env, "batch", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errFail := errors.New("fail")
+	var wg sync.WaitGroup
+	errs := make([]error, env.MaxBatchSize)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = env.Batch(func(txn *Txn) error {
+				switch i {
+				case 3:
+					return errFail
+				case 5:
+					panic("boom")
+				}
+				return txn.Put(db, []byte{byte(i)}, []byte{byte(i)}, 0)
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		switch i {
+		case 3:
+			if err != errFail {
+				t.Errorf("call %d: unexpected error: %v", i, err)
+			}
+		case 5:
+			if err == nil {
+				t.Errorf("call %d: panic was not reported", i)
+			}
+		default:
+			if err != nil {
+				t.Errorf("call %d: unexpected error: %v", i, err)
+			}
+		}
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != uint64(len(errs)-2) {
+			t.Errorf("unexpected entries: %d (!= %d)", stat.Entries, len(errs)-2)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
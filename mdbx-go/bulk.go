@@ -0,0 +1,391 @@
+package mdbx
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+)
+
+// Default values of BulkLoader.BufferSize and BulkLoader.MaxDirty.
+const (
+	DefaultBulkBufferSize = 256 << 20
+	DefaultBulkMaxDirty   = 64 << 20
+)
+
+// bulkPairOverhead approximates the memory used by a buffered pair on top of
+// its key and value.
+const bulkPairOverhead = 48
+
+// bulkCheckInterval is the number of items loaded between checks of the
+// dirty space of the loading transaction.
+const bulkCheckInterval = 1024
+
+// BulkLoader loads a large number of unsorted items into a database.  Items
+// are buffered in memory and sorted, sorted runs are spilled to temporary
+// files whenever the buffer is full, and Load merges the runs and stores the
+// items in ascending order with the Append (or AppendDup) flag, which is much
+// faster than storing them in random order.
+//
+// Load commits a transaction whenever the dirty space of the current one
+// reaches MaxDirty, so the load is not atomic and a failed Load may leave part
+// of the items in the database.
+//
+// Items whose keys are not greater than the keys already in the database are
+// stored without the Append flag.  When the same key is added more than once
+// to a database without DupSort the value added last is kept.
+//
+// A BulkLoader must not be used concurrently.
+type BulkLoader struct {
+	// TmpDir is the directory of the spilled runs.  If empty the default
+	// directory for temporary files is used.
+	TmpDir string
+
+	// BufferSize is the approximate number of bytes buffered in memory
+	// before a sorted run is spilled to disk.
+	BufferSize int
+
+	// MaxDirty is the dirty space (see TxInfo.SpaceDirty) at which Load
+	// commits the current transaction and starts a new one.
+	MaxDirty uint64
+
+	// Cmp and DCmp order keys and duplicate values when they are not ordered
+	// by bytes.Compare in the database.  They must order items the same way as
+	// the comparison functions of the database.
+	Cmp  Cmp
+	DCmp Cmp
+
+	// Progress, if not nil, is called by Load after each commit with the
+	// number of items loaded so far and the total number of items.
+	Progress func(loaded, total uint64)
+
+	env   *Env
+	dbi   DBI
+	dups  int // 1 if dbi is a DupSort database, -1 if not, 0 if unknown
+	pairs []bulkPair
+	size  int
+	runs  []*os.File
+	total uint64
+}
+
+type bulkPair struct {
+	k, v []byte
+}
+
+// NewBulkLoader returns a BulkLoader which loads items into database dbi of
+// env.
+func NewBulkLoader(env *Env, dbi DBI) *BulkLoader {
+	return &BulkLoader{
+		BufferSize: DefaultBulkBufferSize,
+		MaxDirty:   DefaultBulkMaxDirty,
+		env:        env,
+		dbi:        dbi,
+	}
+}
+
+// Add buffers an item to be loaded.  Key and val are copied and may be
+// modified once Add returns.
+func (l *BulkLoader) Add(key, val []byte) error {
+	b := make([]byte, len(key)+len(val))
+	copy(b, key)
+	copy(b[len(key):], val)
+	l.pairs = append(l.pairs, bulkPair{k: b[:len(key):len(key)], v: b[len(key):]})
+	l.size += len(b) + bulkPairOverhead
+	l.total++
+	if l.size >= l.BufferSize {
+		return l.spill()
+	}
+	return nil
+}
+
+// compare orders items by key and, in a DupSort database, by value.
+func (l *BulkLoader) compare(a, b *bulkPair) int {
+	var c int
+	if l.Cmp != nil {
+		c = l.Cmp(a.k, b.k)
+	} else {
+		c = bytes.Compare(a.k, b.k)
+	}
+	if c != 0 || l.dups < 0 {
+		return c
+	}
+	if l.DCmp != nil {
+		return l.DCmp(a.v, b.v)
+	}
+	return bytes.Compare(a.v, b.v)
+}
+
+// sortPairs sorts the buffer.  The sort is stable so that the last value
+// added for a key is stored last.
+func (l *BulkLoader) sortPairs() error {
+	if l.dups == 0 {
+		err := l.env.View(func(txn *Txn) error {
+			flags, err := txn.Flags(l.dbi)
+			if err != nil {
+				return err
+			}
+			l.dups = -1
+			if flags&DupSort != 0 {
+				l.dups = 1
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(l.pairs, func(i, j int) bool {
+		return l.compare(&l.pairs[i], &l.pairs[j]) < 0
+	})
+	return nil
+}
+
+// spill writes the buffer to a temporary file as a sorted run.
+func (l *BulkLoader) spill() error {
+	if err := l.sortPairs(); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(l.TmpDir, "mdbx-bulk-")
+	if err != nil {
+		return err
+	}
+	l.runs = append(l.runs, f)
+
+	w := bufio.NewWriter(f)
+	var hdr [2 * binary.MaxVarintLen64]byte
+	for _, p := range l.pairs {
+		n := binary.PutUvarint(hdr[:], uint64(len(p.k)))
+		n += binary.PutUvarint(hdr[n:], uint64(len(p.v)))
+		if _, err = w.Write(hdr[:n]); err != nil {
+			return err
+		}
+		if _, err = w.Write(p.k); err != nil {
+			return err
+		}
+		if _, err = w.Write(p.v); err != nil {
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	l.pairs = nil
+	l.size = 0
+	return nil
+}
+
+// Load stores every item added so far in the database and resets l.  The
+// temporary files are removed whether or not Load succeeds.
+//
+// Load locks the calling goroutine to its thread while it runs, like
+// Env.Update.
+func (l *BulkLoader) Load() error {
+	defer l.Close()
+
+	if err := l.sortPairs(); err != nil {
+		return err
+	}
+	m := &bulkMerge{l: l}
+	for i, f := range l.runs {
+		m.add(&bulkFileRun{r: bufio.NewReader(f), id: i})
+	}
+	if len(l.pairs) > 0 {
+		// The in-memory run was added last, it wins ties.
+		m.add(&bulkMemRun{pairs: l.pairs, id: len(l.runs)})
+	}
+	if m.err != nil {
+		return m.err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var loaded uint64
+	for m.Len() > 0 {
+		err := l.env.UpdateLocked(func(txn *Txn) error {
+			return l.loadTxn(txn, m, &loaded)
+		})
+		if err != nil {
+			return err
+		}
+		if l.Progress != nil {
+			l.Progress(loaded, l.total)
+		}
+	}
+	return m.err
+}
+
+// loadTxn loads items from m until the dirty space of txn reaches
+// l.MaxDirty.
+func (l *BulkLoader) loadTxn(txn *Txn, m *bulkMerge, loaded *uint64) error {
+	appendFlag := uint(Append)
+	if l.dups > 0 {
+		appendFlag = AppendDup
+	}
+	cur, err := txn.OpenCursor(l.dbi)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for i := 1; m.Len() > 0; i++ {
+		p := m.next()
+		if m.err != nil {
+			return m.err
+		}
+		err = cur.Put(p.k, p.v, appendFlag)
+		if IsErrno(err, KeyMismatch) {
+			// The item does not sort after the last one of the database.
+			err = cur.Put(p.k, p.v, 0)
+		}
+		if err != nil {
+			return err
+		}
+		*loaded++
+
+		if i%bulkCheckInterval == 0 {
+			full, err := spaceExhausted(txn, l.MaxDirty, 0)
+			if err != nil || full {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close discards the items added to l and removes its temporary files.
+func (l *BulkLoader) Close() error {
+	var err error
+	for _, f := range l.runs {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+		if e := os.Remove(f.Name()); e != nil && err == nil {
+			err = e
+		}
+	}
+	l.runs = nil
+	l.pairs = nil
+	l.size = 0
+	l.total = 0
+	return err
+}
+
+// bulkRun is a sorted source of items.  Next returns io.EOF once the run is
+// exhausted.
+type bulkRun interface {
+	next() (bulkPair, error)
+	order() int
+}
+
+type bulkMemRun struct {
+	pairs []bulkPair
+	id    int
+}
+
+func (r *bulkMemRun) next() (bulkPair, error) {
+	if len(r.pairs) == 0 {
+		return bulkPair{}, io.EOF
+	}
+	p := r.pairs[0]
+	r.pairs = r.pairs[1:]
+	return p, nil
+}
+
+func (r *bulkMemRun) order() int { return r.id }
+
+type bulkFileRun struct {
+	r  *bufio.Reader
+	id int
+}
+
+var errBulkRunCorrupt = errors.New("corrupt bulk loader run")
+
+func (r *bulkFileRun) next() (bulkPair, error) {
+	kn, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return bulkPair{}, err
+	}
+	vn, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return bulkPair{}, errBulkRunCorrupt
+	}
+	b := make([]byte, kn+vn)
+	if _, err = io.ReadFull(r.r, b); err != nil {
+		return bulkPair{}, errBulkRunCorrupt
+	}
+	return bulkPair{k: b[:kn:kn], v: b[kn:]}, nil
+}
+
+func (r *bulkFileRun) order() int { return r.id }
+
+// bulkMerge merges sorted runs with a heap holding the head of each run.
+type bulkMerge struct {
+	l     *BulkLoader
+	heads []bulkHead
+	err   error
+}
+
+type bulkHead struct {
+	p   bulkPair
+	run bulkRun
+}
+
+func (m *bulkMerge) Len() int { return len(m.heads) }
+
+func (m *bulkMerge) Less(i, j int) bool {
+	c := m.l.compare(&m.heads[i].p, &m.heads[j].p)
+	if c != 0 {
+		return c < 0
+	}
+	return m.heads[i].run.order() < m.heads[j].run.order()
+}
+
+func (m *bulkMerge) Swap(i, j int) { m.heads[i], m.heads[j] = m.heads[j], m.heads[i] }
+
+func (m *bulkMerge) Push(x interface{}) { m.heads = append(m.heads, x.(bulkHead)) }
+
+func (m *bulkMerge) Pop() interface{} {
+	h := m.heads[len(m.heads)-1]
+	m.heads = m.heads[:len(m.heads)-1]
+	return h
+}
+
+// add reads the first item of run and adds the run to the merge.
+func (m *bulkMerge) add(run bulkRun) {
+	p, err := run.next()
+	if err == io.EOF {
+		return
+	}
+	if err != nil {
+		m.err = err
+		return
+	}
+	heap.Push(m, bulkHead{p: p, run: run})
+}
+
+// next returns the smallest item of the merge.  M must not be empty.
+func (m *bulkMerge) next() bulkPair {
+	h := &m.heads[0]
+	p := h.p
+	next, err := h.run.next()
+	switch {
+	case err == io.EOF:
+		heap.Pop(m)
+	case err != nil:
+		m.err = err
+	default:
+		h.p = next
+		heap.Fix(m, 0)
+	}
+	return p
+}
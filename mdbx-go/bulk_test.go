@@ -0,0 +1,153 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestBulkLoader(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "bulk", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp, err := ioutil.TempDir("", "mdbx-bulk-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	l := NewBulkLoader(env, db)
+	l.TmpDir = tmp
+	l.BufferSize = 64 << 10
+	l.MaxDirty = 32 << 10
+	var calls int
+	var loaded, total uint64
+	l.Progress = func(n, m uint64) {
+		calls++
+		loaded, total = n, m
+	}
+
+	const n = 10000
+	for _, i := range rand.Perm(n) {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+		if err := l.Add(k[:], []byte(fmt.Sprintf("old%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// later values of a key replace earlier ones.
+	for i := 0; i < n; i += 100 {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+		if err := l.Add(k[:], []byte(fmt.Sprintf("new%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(l.runs) < 2 {
+		t.Errorf("expected spilled runs, got %d", len(l.runs))
+	}
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if calls < 2 {
+		t.Errorf("expected several commits, got %d", calls)
+	}
+	if loaded != n+n/100 || total != loaded {
+		t.Errorf("unexpected progress: %d/%d", loaded, total)
+	}
+	files, err := ioutil.ReadDir(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("temporary files were not removed: %d", len(files))
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != n {
+			t.Errorf("unexpected entries: %d (!= %d)", stat.Entries, n)
+		}
+		for i := 0; i < n; i += 50 {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			v, err := txn.Get(db, k[:])
+			if err != nil {
+				return err
+			}
+			want := fmt.Sprintf("old%d", i)
+			if i%100 == 0 {
+				want = fmt.Sprintf("new%d", i)
+			}
+			if string(v) != want {
+				t.Errorf("unexpected value for %d: %q (!= %q)", i, v, want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBulkLoader_dupSort(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "bulkdup", Create|DupSort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// existing items force the loader off the append path.
+	err = env.Update(func(txn *Txn) error {
+		for _, kv := range [][2]string{{"b", "2"}, {"d", "1"}} {
+			if err := txn.Put(db, []byte(kv[0]), []byte(kv[1]), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewBulkLoader(env, db)
+	for _, kv := range [][2]string{{"c", "1"}, {"b", "3"}, {"a", "1"}, {"b", "1"}, {"e", "1"}, {"b", "2"}} {
+		if err := l.Add([]byte(kv[0]), []byte(kv[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		var got string
+		s := NewScanner(cur).Dups()
+		for s.Scan() {
+			got += string(s.Key()) + string(s.Val()) + " "
+		}
+		if want := "a1 b1 b2 b3 c1 d1 e1 "; got != want {
+			t.Errorf("unexpected items: %q (!= %q)", got, want)
+		}
+		return s.Err()
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,229 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CmpParamMax is the largest parameter of CmpExcludeSuffix and
+// CmpPrefixReversed with a comparison function of its own.  Larger parameters
+// are bound to one of CmpParamSlots shared comparison functions.
+const CmpParamMax = C.MDBXGO_CMP_PARAM_MAX
+
+// CmpParamSlots is the number of distinct comparison functions with a
+// parameter larger than CmpParamMax which can be obtained from
+// CmpExcludeSuffix and CmpPrefixReversed.
+const CmpParamSlots = C.MDBXGO_CMP_PARAM_SLOT_MAX
+
+type cmpParam struct {
+	kind  C.int
+	param int
+}
+
+var cmpslotmu sync.Mutex
+var cmpslots = map[cmpParam]CmpFunc{}
+
+// paramCmp returns the native comparison function of the given kind for
+// param.  Parameters up to CmpParamMax are looked up in a table, larger ones
+// are bound to a parameter slot on first use and keep it for the life of the
+// process.
+func paramCmp(kind C.int, param int) (CmpFunc, error) {
+	if param < 0 {
+		return nil, fmt.Errorf("negative comparator parameter %d", param)
+	}
+	if param <= CmpParamMax {
+		return C.mdbxgo_builtin_cmp(kind, C.size_t(param)), nil
+	}
+
+	cmpslotmu.Lock()
+	defer cmpslotmu.Unlock()
+	key := cmpParam{kind, param}
+	if f, ok := cmpslots[key]; ok {
+		return f, nil
+	}
+	slot := len(cmpslots)
+	if slot >= CmpParamSlots {
+		return nil, fmt.Errorf("comparator parameter %d: all %d slots are used", param, CmpParamSlots)
+	}
+	f := C.mdbxgo_param_cmp(C.size_t(slot), kind, C.size_t(param))
+	cmpslots[key] = f
+	setCmpName(f, cmpParamName(kind, param))
+	return f, nil
+}
+
+func mustParamCmp(kind C.int, param int) CmpFunc {
+	f, err := paramCmp(kind, param)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// CmpExcludeSuffix returns a native comparison function ordering items
+// lexically while ignoring their last n bytes.  Items shorter than n bytes are
+// compared whole.  Items equal but for their suffix compare equal, so in a
+// DupSort database only one of them can be stored per key.
+//
+// Any n up to CmpParamMax has a comparison function of its own.  MDBX
+// comparison functions carry no context, so a larger n takes one of
+// CmpParamSlots slots, shared with CmpPrefixReversed, for the life of the
+// process.  CmpExcludeSuffix panics if n is negative or if a larger n needs a
+// slot and none is left.
+func CmpExcludeSuffix(n int) CmpFunc {
+	return mustParamCmp(C.MDBXGO_CMP_EXCLUDE_SUFFIX, n)
+}
+
+// CmpPrefixReversed returns a native comparison function ordering items
+// lexically by their first k bytes, then in descending lexical order of the
+// remaining bytes.  It suits keys made of a k byte identifier followed by a
+// version which should be visited newest first.
+//
+// Like CmpExcludeSuffix, a k larger than CmpParamMax takes one of
+// CmpParamSlots slots for the life of the process.  CmpPrefixReversed panics
+// if k is negative or if a larger k needs a slot and none is left.
+func CmpPrefixReversed(k int) CmpFunc {
+	return mustParamCmp(C.MDBXGO_CMP_PREFIX_REVERSED, k)
+}
+
+// CmpUint64BEPrefix returns a native comparison function ordering items by
+// their first 8 bytes read as a big-endian integer, then lexically by the
+// remaining bytes.  Items shorter than 8 bytes sort before all others.
+func CmpUint64BEPrefix() CmpFunc {
+	return C.mdbxgo_builtin_cmp(C.MDBXGO_CMP_UINT64BE_PREFIX, 0)
+}
+
+// CmpReverseLexical returns a native comparison function ordering items in
+// descending lexical order.  Unlike the ReverseKey flag, which compares bytes
+// from the end of keys, it reverses the default order.
+func CmpReverseLexical() CmpFunc {
+	return C.mdbxgo_builtin_cmp(C.MDBXGO_CMP_REVERSE_LEXICAL, 0)
+}
+
+// CmpLengthThenBytes returns a native comparison function ordering shorter
+// items first and items of the same length lexically.
+func CmpLengthThenBytes() CmpFunc {
+	return C.mdbxgo_builtin_cmp(C.MDBXGO_CMP_LENGTH_THEN_BYTES, 0)
+}
+
+// LookupCmp returns the comparison function with the given name, as used in
+// configuration files.  Besides the names of Go comparison functions
+// registered with RegisterCmp, the names are
+//
+//		lexical             the default order of mdbx (a nil CmpFunc)
+//		exclude-suffix-N    CmpExcludeSuffix(N)
+//		prefix-K-reversed   CmpPrefixReversed(K)
+//		uint64be-prefix     CmpUint64BEPrefix()
+//		reverse-lexical     CmpReverseLexical()
+//		length-then-bytes   CmpLengthThenBytes()
+func LookupCmp(name string) (CmpFunc, error) {
+	if f, ok := lookupGoCmp(name); ok {
+		return f, nil
+	}
+	return lookupBuiltinCmp(name)
+}
+
+func lookupBuiltinCmp(name string) (CmpFunc, error) {
+	switch name {
+	case "lexical":
+		return nil, nil
+	case "uint64be-prefix":
+		return CmpUint64BEPrefix(), nil
+	case "reverse-lexical":
+		return CmpReverseLexical(), nil
+	case "length-then-bytes":
+		return CmpLengthThenBytes(), nil
+	}
+	if kind, param, ok := parseParamCmpName(name); ok {
+		return paramCmp(kind, param)
+	}
+	return nil, fmt.Errorf("unknown comparator %q", name)
+}
+
+// isBuiltinCmpName reports whether LookupCmp resolves name to a built-in
+// comparison function.  Unlike lookupBuiltinCmp it binds no parameter slot.
+func isBuiltinCmpName(name string) bool {
+	switch name {
+	case "lexical", "uint64be-prefix", "reverse-lexical", "length-then-bytes":
+		return true
+	}
+	_, _, ok := parseParamCmpName(name)
+	return ok
+}
+
+// parseParamCmpName parses the name of a comparison function taking a
+// parameter.
+func parseParamCmpName(name string) (kind C.int, param int, ok bool) {
+	if s := strings.TrimPrefix(name, "exclude-suffix-"); s != name {
+		if n, ok := parseCmpParam(s); ok {
+			return C.MDBXGO_CMP_EXCLUDE_SUFFIX, n, true
+		}
+	}
+	if s := strings.TrimPrefix(name, "prefix-"); s != name && strings.HasSuffix(s, "-reversed") {
+		if k, ok := parseCmpParam(strings.TrimSuffix(s, "-reversed")); ok {
+			return C.MDBXGO_CMP_PREFIX_REVERSED, k, true
+		}
+	}
+	return 0, 0, false
+}
+
+// CmpName returns the name under which LookupCmp finds f, or an empty string
+// if f is neither a built-in nor a registered comparison function.
+func CmpName(f CmpFunc) string {
+	if f == nil {
+		return "lexical"
+	}
+	cmpnamemu.Lock()
+	defer cmpnamemu.Unlock()
+	return cmpNames()[f]
+}
+
+// cmpnames maps native comparison functions to their names.  It is filled
+// with the built-in functions on first use, then as parameter slots are bound
+// and Go comparison functions registered.
+var cmpnamemu sync.Mutex
+var cmpnames map[CmpFunc]string
+
+// cmpNames returns cmpnames, which it fills first if needed.  The caller must
+// hold cmpnamemu.
+func cmpNames() map[CmpFunc]string {
+	if cmpnames != nil {
+		return cmpnames
+	}
+	cmpnames = map[CmpFunc]string{
+		CmpUint64BEPrefix():  "uint64be-prefix",
+		CmpReverseLexical():  "reverse-lexical",
+		CmpLengthThenBytes(): "length-then-bytes",
+	}
+	for n := 0; n <= CmpParamMax; n++ {
+		for _, kind := range []C.int{C.MDBXGO_CMP_EXCLUDE_SUFFIX, C.MDBXGO_CMP_PREFIX_REVERSED} {
+			cmpnames[C.mdbxgo_builtin_cmp(kind, C.size_t(n))] = cmpParamName(kind, n)
+		}
+	}
+	return cmpnames
+}
+
+// setCmpName records name as the name of f.
+func setCmpName(f CmpFunc, name string) {
+	cmpnamemu.Lock()
+	cmpNames()[f] = name
+	cmpnamemu.Unlock()
+}
+
+func cmpParamName(kind C.int, param int) string {
+	if kind == C.MDBXGO_CMP_PREFIX_REVERSED {
+		return "prefix-" + strconv.Itoa(param) + "-reversed"
+	}
+	return "exclude-suffix-" + strconv.Itoa(param)
+}
+
+func parseCmpParam(s string) (int, bool) {
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n >= 0
+}
@@ -0,0 +1,92 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CmpGoSlots is the number of Go comparison functions which can be
+// registered with RegisterCmp.
+const CmpGoSlots = C.MDBXGO_GOCMP_SLOT_MAX
+
+var gocmpmu sync.Mutex
+var gocmps [CmpGoSlots]Cmp
+var gocmpnames = map[string]int{}
+
+// mdbxgoCmpBridge provides the static C functions of the comparison function
+// slots.  It wraps the items in slices without copying them and dispatches to
+// the Go function registered in slot.
+
+//export mdbxgoCmpBridge
+func mdbxgoCmpBridge(slot C.int, a, b *C.MDBX_val) C.int {
+	c := gocmps[slot](cmpBytes(a), cmpBytes(b))
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
+}
+
+func cmpBytes(val *C.MDBX_val) []byte {
+	if val.iov_len == 0 {
+		return nil
+	}
+	return getBytes(val)
+}
+
+// RegisterCmp registers fn as a comparison function named name and returns
+// its CmpFunc, which may be passed to Txn.OpenDBI like the built-in ones.  The
+// name is resolved by LookupCmp and reported by CmpName.  At most CmpGoSlots
+// functions can be registered, for the life of the process, so RegisterCmp is
+// meant to be called once per ordering, typically from an init function.
+//
+// Fn is called by mdbx for every comparison it makes, while it holds the
+// locks of the transaction, so it must be a pure function of its arguments:
+// it must not retain a or b, which reference the memory map, must not use any
+// transaction and must not panic, since a panic cannot unwind mdbx.
+//
+// A Go comparison function is much slower than a native one.  Every
+// comparison crosses from C back into Go, which costs roughly 100ns against a
+// few nanoseconds for a native function, and a lookup or insertion makes
+// about log2(n) comparisons.  See BenchmarkCmp_go.  Prefer a built-in
+// comparison function (see LookupCmp) whenever one matches the ordering.
+func RegisterCmp(name string, fn Cmp) (CmpFunc, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("comparator %q: nil function", name)
+	}
+	if isBuiltinCmpName(name) {
+		return nil, fmt.Errorf("comparator %q: name of a built-in comparator", name)
+	}
+
+	gocmpmu.Lock()
+	defer gocmpmu.Unlock()
+	if _, ok := gocmpnames[name]; ok {
+		return nil, fmt.Errorf("comparator %q: already registered", name)
+	}
+	slot := len(gocmpnames)
+	if slot >= CmpGoSlots {
+		return nil, fmt.Errorf("comparator %q: all %d slots are used", name, CmpGoSlots)
+	}
+	gocmps[slot] = fn
+	gocmpnames[name] = slot
+	f := C.mdbxgo_gocmp(C.size_t(slot))
+	setCmpName(f, name)
+	return f, nil
+}
+
+func lookupGoCmp(name string) (CmpFunc, bool) {
+	gocmpmu.Lock()
+	slot, ok := gocmpnames[name]
+	gocmpmu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return C.mdbxgo_gocmp(C.size_t(slot)), true
+}
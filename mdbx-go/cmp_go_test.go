@@ -0,0 +1,183 @@
+package mdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"syscall"
+	"testing"
+)
+
+// cmpLengthThenBytesGo orders items like CmpLengthThenBytes.
+func cmpLengthThenBytesGo(a, b []byte) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return bytes.Compare(a, b)
+}
+
+var testGoCmp = mustRegisterCmp("test-length-then-bytes", cmpLengthThenBytesGo)
+
+func mustRegisterCmp(name string, fn Cmp) CmpFunc {
+	f, err := RegisterCmp(name, fn)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func TestRegisterCmp(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	if f, err := LookupCmp("test-length-then-bytes"); err != nil || f != testGoCmp {
+		t.Errorf("unexpected lookup: %v", err)
+	}
+	if name := CmpName(testGoCmp); name != "test-length-then-bytes" {
+		t.Errorf("unexpected name: %q", name)
+	}
+	if _, err := RegisterCmp("test-length-then-bytes", cmpLengthThenBytesGo); err == nil {
+		t.Errorf("registered a name twice")
+	}
+	if _, err := RegisterCmp("reverse-lexical", cmpLengthThenBytesGo); err == nil {
+		t.Errorf("registered a built-in name")
+	}
+	cmpslotmu.Lock()
+	slots := len(cmpslots)
+	cmpslotmu.Unlock()
+	if _, err := RegisterCmp("exclude-suffix-5000", cmpLengthThenBytesGo); err == nil {
+		t.Errorf("registered a built-in name with a parameter")
+	}
+	cmpslotmu.Lock()
+	if len(cmpslots) != slots {
+		t.Errorf("registration bound a parameter slot")
+	}
+	cmpslotmu.Unlock()
+
+	var dbi DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		dbi, err = txn.OpenDBI("testdb", Create, testGoCmp, nil)
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"ccc", "b", "aa", "a", "bb"} {
+			if err := txn.Put(dbi, []byte(k), nil, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(dbi)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		var keys []string
+		s := NewScanner(cur)
+		for s.Scan() {
+			keys = append(keys, string(s.Key()))
+		}
+		if err := s.Err(); err != nil {
+			return err
+		}
+		if want := "a b aa bb ccc"; fmtKeys(keys) != want {
+			t.Errorf("unexpected order: %q (!= %q)", fmtKeys(keys), want)
+		}
+		if c := txn.Cmp(dbi, []byte("zz"), []byte("aaa")); c != -1 {
+			t.Errorf("unexpected comparison: %d", c)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the database cannot be opened with another comparator.
+	err = env.View(func(txn *Txn) error {
+		_, err := txn.OpenDBI("testdb", 0, CmpLengthThenBytes(), nil)
+		return err
+	})
+	if !IsErrnoSys(err, syscall.EINVAL) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func fmtKeys(keys []string) string {
+	var b bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+	}
+	return b.String()
+}
+
+func TestSchema_cmp(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	schema := &Schema{Tables: []TableSpec{{Name: "t", Cmp: testGoCmp}}}
+	if err := schema.Open(env); err != nil {
+		t.Fatal(err)
+	}
+	env.CloseDBI(schema.DBI("t"))
+
+	schema = &Schema{Tables: []TableSpec{{Name: "t", Cmp: CmpReverseLexical()}}}
+	err := schema.Open(env)
+	var cerr *SchemaCmpError
+	if !errors.As(err, &cerr) || cerr.Recorded != "test-length-then-bytes,lexical" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// BenchmarkCmp_go compares the cost of inserting random keys into databases
+// ordered by a native and by an equivalent Go comparison function.
+func BenchmarkCmp_go(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		cmp  CmpFunc
+	}{
+		{"c", CmpLengthThenBytes()},
+		{"go", testGoCmp},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			env := setup(b)
+			defer clean(env, b)
+			if err := env.SetGeometry(-1, -1, 1<<30, -1, -1, -1); err != nil {
+				b.Fatal(err)
+			}
+
+			var dbi DBI
+			err := env.Update(func(txn *Txn) (err error) {
+				dbi, err = txn.OpenDBI("bench", Create, bench.cmp, nil)
+				return err
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			rng := rand.New(rand.NewSource(1))
+			k := make([]byte, 8)
+			b.ResetTimer()
+			err = env.Update(func(txn *Txn) error {
+				for i := 0; i < b.N; i++ {
+					binary.BigEndian.PutUint64(k, rng.Uint64())
+					if err := txn.Put(dbi, k, nil, 0); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
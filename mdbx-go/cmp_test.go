@@ -0,0 +1,203 @@
+package mdbx
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestBuiltinCmp(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	for _, test := range []struct {
+		name   string
+		sorted [][]byte // in ascending order, with no equal items
+		equal  [][2][]byte
+	}{
+		{
+			name:   "exclude-suffix-2",
+			sorted: [][]byte{{}, {0}, {1}, {1, 0, 9, 9}, {1, 1, 0, 0}, {2, 0, 0}},
+			equal:  [][2][]byte{{{1, 0, 0}, {1, 9, 9}}, {{1, 2}, {}}},
+		},
+		{
+			name:   "prefix-2-reversed",
+			sorted: [][]byte{{0}, {0, 1, 9}, {0, 1, 1, 5}, {0, 1, 1}, {0, 1}, {1, 0, 2}, {1, 0, 1}},
+		},
+		{
+			name: "uint64be-prefix",
+			sorted: [][]byte{
+				{0xff},
+				{0, 0, 0, 0, 0, 0, 0, 1},
+				{0, 0, 0, 0, 0, 0, 0, 1, 0},
+				{0, 0, 0, 0, 0, 0, 1, 0},
+				{1, 0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+		{
+			name:   "reverse-lexical",
+			sorted: [][]byte{{2}, {1, 1}, {1, 0}, {1}, {}},
+		},
+		{
+			name:   "length-then-bytes",
+			sorted: [][]byte{{}, {9}, {0, 0}, {0, 1}, {1, 0}, {0, 0, 0}},
+		},
+	} {
+		cmp, err := LookupCmp(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = env.Update(func(txn *Txn) error {
+			dbi, err := txn.OpenDBI(test.name, Create|DupSort, cmp, cmp)
+			if err != nil {
+				return err
+			}
+			for i, a := range test.sorted {
+				for j, b := range test.sorted {
+					if c := sign(txn.Cmp(dbi, a, b)); c != sign(i-j) {
+						t.Errorf("%s: Cmp(%x, %x) = %d", test.name, a, b, c)
+					}
+					if c := sign(txn.DCmp(dbi, a, b)); c != sign(i-j) {
+						t.Errorf("%s: DCmp(%x, %x) = %d", test.name, a, b, c)
+					}
+				}
+			}
+			for _, p := range test.equal {
+				if c := txn.Cmp(dbi, p[0], p[1]); c != 0 {
+					t.Errorf("%s: Cmp(%x, %x) = %d", test.name, p[0], p[1], c)
+				}
+			}
+
+			// the database stores items in the same order.
+			for i := len(test.sorted) - 1; i >= 0; i-- {
+				k := test.sorted[i]
+				if len(k) == 0 {
+					continue
+				}
+				if err := txn.Put(dbi, k, nil, 0); err != nil {
+					return err
+				}
+			}
+			cur, err := txn.OpenCursor(dbi)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+			var keys [][]byte
+			for {
+				k, _, err := cur.Get(nil, nil, Next)
+				if IsNotFound(err) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				keys = append(keys, k)
+			}
+			i := 0
+			for _, k := range test.sorted {
+				if len(k) == 0 {
+					continue
+				}
+				if i >= len(keys) || !bytes.Equal(keys[i], k) {
+					t.Errorf("%s: unexpected order: %x", test.name, keys)
+					break
+				}
+				i++
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestLookupCmp(t *testing.T) {
+	for _, name := range []string{"exclude-suffix-64", "exclude-suffix-65", "prefix-0-reversed", "lexical"} {
+		if _, err := LookupCmp(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "exclude-suffix--1", "prefix-8", "prefix-x-reversed", "uint32"} {
+		if _, err := LookupCmp(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if cmp, _ := LookupCmp("exclude-suffix-32"); cmp != CmpExcludeSuffix(32) {
+		t.Errorf("exclude-suffix-32 is not CmpExcludeSuffix(32)")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	CmpPrefixReversed(-1)
+}
+
+func TestCmpExcludeSuffix_slots(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	const n = 100
+	cmp := CmpExcludeSuffix(n)
+	if CmpExcludeSuffix(n) != cmp {
+		t.Errorf("CmpExcludeSuffix(%d) is bound to another slot on reuse", n)
+	}
+	if CmpPrefixReversed(n) == cmp {
+		t.Errorf("CmpPrefixReversed(%d) shares the slot of CmpExcludeSuffix(%d)", n, n)
+	}
+	if name := CmpName(cmp); name != "exclude-suffix-100" {
+		t.Errorf("unexpected name: %q", name)
+	}
+	if f, err := LookupCmp("exclude-suffix-100"); err != nil || f != cmp {
+		t.Errorf("exclude-suffix-100 is not CmpExcludeSuffix(100): %v", err)
+	}
+
+	item := func(head, tail byte) []byte {
+		return append(bytes.Repeat([]byte{head}, 8), bytes.Repeat([]byte{tail}, n)...)
+	}
+	err := env.Update(func(txn *Txn) error {
+		dbi, err := txn.OpenDBI("testdb", Create, cmp, nil)
+		if err != nil {
+			return err
+		}
+		if c := txn.Cmp(dbi, item(1, 0), item(1, 9)); c != 0 {
+			t.Errorf("items differing by their suffix: Cmp = %d", c)
+		}
+		if c := txn.Cmp(dbi, item(1, 9), item(2, 0)); c >= 0 {
+			t.Errorf("items differing before their suffix: Cmp = %d", c)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// parameters keep their slot once all slots are used.
+	for k := 1000; ; k++ {
+		if _, err := LookupCmp("exclude-suffix-" + strconv.Itoa(k)); err != nil {
+			break
+		}
+		if k > 1000+CmpParamSlots {
+			t.Fatalf("more than %d slots", CmpParamSlots)
+		}
+	}
+	if CmpExcludeSuffix(n) != cmp {
+		t.Errorf("CmpExcludeSuffix(%d) changed once all slots are used", n)
+	}
+	if _, err := LookupCmp("prefix-100-reversed"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,80 @@
+package mdbx
+
+import (
+	"context"
+)
+
+// ViewCtx behaves like View but ties the transaction to ctx.  Once ctx is
+// done the transaction is reset at the next operation fn performs on it (or
+// on one of its cursors), which releases its snapshot, and that operation and
+// every later one fail with an *OpError wrapping ctx.Err().  A call in
+// progress when ctx is done is not interrupted.
+//
+// Use errors.Is to test the error returned by ViewCtx for context.Canceled or
+// context.DeadlineExceeded.
+func (env *Env) ViewCtx(ctx context.Context, fn TxnOp) error {
+	return env.runCtx(ctx, false, Readonly, fn)
+}
+
+// UpdateCtx behaves like Update but ties the transaction to ctx.  Once ctx is
+// done the transaction is aborted at the next operation fn performs on it (or
+// on one of its cursors or subtransactions), which releases the write lock,
+// and that operation and every later one fail with an *OpError wrapping
+// ctx.Err().  The transaction is never committed if ctx is done by the time
+// fn returns.
+//
+// See Update regarding thread locking.
+func (env *Env) UpdateCtx(ctx context.Context, fn TxnOp) error {
+	return env.runCtx(ctx, true, 0, fn)
+}
+
+// RunTxnCtx behaves like RunTxn but ties the transaction to ctx.  See ViewCtx
+// and UpdateCtx.
+func (env *Env) RunTxnCtx(ctx context.Context, flags uint, fn TxnOp) error {
+	return env.runCtx(ctx, false, flags, fn)
+}
+
+func (env *Env) runCtx(ctx context.Context, lock bool, flags uint, fn TxnOp) error {
+	if err := ctx.Err(); err != nil {
+		return &OpError{Op: "mdbx_txn_begin", Errno: err}
+	}
+	return env.run(lock, flags, func(txn *Txn) error {
+		txn.ctx = ctx
+		return fn(txn)
+	})
+}
+
+// Context returns the context txn was created with by ViewCtx, UpdateCtx or
+// RunTxnCtx (or that of its parent), or context.Background() if there is
+// none.
+func (txn *Txn) Context() context.Context {
+	if txn.ctx == nil {
+		return context.Background()
+	}
+	return txn.ctx
+}
+
+// checkCtx returns an error wrapping the context error once the context of
+// txn is done, and releases the resources held by txn the first time it does.
+// op names the operation which was prevented.  Txn may be nil, as it is for
+// closed cursors.
+func (txn *Txn) checkCtx(op string) error {
+	if txn == nil || txn.ctx == nil {
+		return nil
+	}
+	err := txn.ctx.Err()
+	if err == nil {
+		return nil
+	}
+	if !txn.cancelled {
+		txn.cancelled = true
+		if txn.readonly {
+			// A readonly txn is only reset, the managing function aborts
+			// it once fn returns.
+			txn.reset()
+		} else {
+			txn.abort()
+		}
+	}
+	return &OpError{Op: op, Errno: err}
+}
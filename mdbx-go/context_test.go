@@ -0,0 +1,135 @@
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEnv_ViewCtx(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "ctx", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		for i := 0; i < 10; i++ {
+			if err := txn.Put(db, []byte{byte(i)}, []byte{byte(i)}, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var n int
+	err = env.ViewCtx(ctx, func(txn *Txn) error {
+		if txn.Context() != ctx {
+			t.Errorf("unexpected context")
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		for {
+			_, _, err := cur.Get(nil, nil, Next)
+			if IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			n++
+			if n == 5 {
+				cancel()
+			}
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("unexpected number of items: %d (!= 5)", n)
+	}
+
+	// fn ignoring the cancellation does not make the txn commit.
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	err = env.ViewCtx(ctx2, func(txn *Txn) error {
+		cancel2()
+		if _, err := txn.Get(db, []byte{0}); !errors.Is(err, context.Canceled) {
+			t.Errorf("get: unexpected error: %v", err)
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancellation ignored by fn: unexpected error: %v", err)
+	}
+
+	var called bool
+	err = env.ViewCtx(ctx, func(txn *Txn) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if called {
+		t.Errorf("fn called with a done context")
+	}
+}
+
+func TestEnv_UpdateCtx(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "ctx", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = env.UpdateCtx(ctx, func(txn *Txn) error {
+		if err := txn.Put(db, []byte("k0"), []byte("v0"), 0); err != nil {
+			return err
+		}
+		return txn.Sub(func(txn *Txn) error {
+			cancel()
+			err := txn.Put(db, []byte("k1"), []byte("v1"), 0)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			// fn ignores the error, the transaction must not be committed.
+			return nil
+		})
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		_, err := txn.Get(db, []byte("k0"))
+		if !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// the write lock must have been released.
+	err = env.UpdateCtx(context.Background(), func(txn *Txn) error {
+		return txn.Put(db, []byte("k0"), []byte("v0"), 0)
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
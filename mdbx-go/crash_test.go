@@ -0,0 +1,360 @@
+//go:build !windows
+// +build !windows
+
+package mdbx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// crashWriterEnv holds the path of the environment written by a crash writer
+// process.  Test binaries run with it set execute TestCrashWriterProcess only.
+const crashWriterEnv = "MDBXGO_CRASH_WRITER"
+
+// crashDirEnv may name the directory in which the crash tests create their
+// environments, e.g. a tmpfs or a loop device mount.
+const crashDirEnv = "MDBXGO_CRASH_DIR"
+
+var (
+	crashLastKey = []byte("last")
+	crashBlobKey = []byte("blob")
+)
+
+// crashValue returns the value written for seq, n bytes long.
+func crashValue(seq uint64, n int) []byte {
+	v := make([]byte, n)
+	binary.BigEndian.PutUint64(v, seq)
+	for i := 8; i < n; i++ {
+		v[i] = byte(seq*31 + uint64(i))
+	}
+	return v
+}
+
+func crashItem(seq uint64) []byte { return crashValue(seq, 16+int(seq%200)) }
+func crashBlob(seq uint64) []byte { return crashValue(seq, 3*4096+int(seq%100)) }
+
+// TestCrashWriterProcess is the body of the processes started by
+// runCrashWriter.  It commits transactions until it is killed.  Transaction
+// seq adds the item seq, rewrites the blob, whose overflow pages are freed
+// every time, and records seq as the last one.  Every commit is acknowledged
+// with "ack seq" on stdout once it returned, and every 16th commit is
+// followed by a forced sync acknowledged with "sync seq".
+func TestCrashWriterProcess(t *testing.T) {
+	path := os.Getenv(crashWriterEnv)
+	if path == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	flags, err := strconv.ParseUint(os.Args[len(os.Args)-1], 10, 0)
+	if err != nil {
+		fmt.Println("error", err)
+		return
+	}
+	env, err := NewEnv()
+	if err != nil {
+		fmt.Println("error", err)
+		return
+	}
+	defer env.Close()
+	if err := env.SetMaxDBs(1); err != nil {
+		fmt.Println("error", err)
+		return
+	}
+	if err := env.SetGeometry(-1, -1, 1<<30, -1, -1, -1); err != nil {
+		fmt.Println("error", err)
+		return
+	}
+	if err := env.Open(path, uint(flags), 0644); err != nil {
+		fmt.Println("error", err)
+		return
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	for {
+		var seq uint64
+		err := env.Update(func(txn *Txn) error {
+			dbi, err := txn.OpenDBI("crash", Create, nil, nil)
+			if err != nil {
+				return err
+			}
+			last, err := txn.Get(dbi, crashLastKey)
+			switch {
+			case IsNotFound(err):
+			case err != nil:
+				return err
+			default:
+				seq = binary.BigEndian.Uint64(last)
+			}
+			seq++
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], seq)
+			if err := txn.Put(dbi, k[:], crashItem(seq), 0); err != nil {
+				return err
+			}
+			if err := txn.Put(dbi, crashBlobKey, crashBlob(seq), 0); err != nil {
+				return err
+			}
+			return txn.Put(dbi, crashLastKey, k[:], 0)
+		})
+		if err != nil {
+			fmt.Fprintln(out, "error", err)
+			out.Flush()
+			return
+		}
+		fmt.Fprintln(out, "ack", seq)
+		if seq%16 == 0 {
+			if err := env.Sync(true, false); err != nil {
+				fmt.Fprintln(out, "error", err)
+				out.Flush()
+				return
+			}
+			fmt.Fprintln(out, "sync", seq)
+		}
+		out.Flush()
+	}
+}
+
+// crashAcks are the acknowledgements received from crash writer processes.
+type crashAcks struct {
+	acked  uint64 // last acknowledged commit
+	synced uint64 // last acknowledged sync
+}
+
+// runCrashWriter runs a crash writer process on the environment at path and
+// kills it with SIGKILL after n more commits were acknowledged.  The output
+// written by the process before it died is accounted in acks.
+func runCrashWriter(t *testing.T, path string, flags uint, n int, acks *crashAcks) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashWriterProcess$", "--", strconv.FormatUint(uint64(flags), 10))
+	cmd.Env = append(os.Environ(), crashWriterEnv+"="+path)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()
+
+	killed := false
+	out := bufio.NewScanner(stdout)
+	for out.Scan() {
+		var what string
+		var seq uint64
+		if _, err := fmt.Sscan(out.Text(), &what, &seq); err != nil {
+			cmd.Process.Kill()
+			t.Fatalf("writer: %q", out.Text())
+		}
+		switch what {
+		case "ack":
+			acks.acked = seq
+			n--
+		case "sync":
+			acks.synced = seq
+		}
+		if n == 0 && !killed {
+			// Reading on collects the acknowledgements which were written
+			// before the process died.
+			cmd.Process.Kill()
+			killed = true
+		}
+	}
+	if !killed {
+		t.Fatalf("writer exited: %v", out.Err())
+	}
+}
+
+// verifyCrash checks the content and the pages of env and returns the last
+// transaction found in it.
+func verifyCrash(t *testing.T, env *Env) uint64 {
+	var last uint64
+	err := env.View(func(txn *Txn) error {
+		dbi, err := txn.OpenDBI("crash", 0, nil, nil)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v, err := txn.Get(dbi, crashLastKey)
+		if err != nil {
+			return err
+		}
+		last = binary.BigEndian.Uint64(v)
+		v, err = txn.Get(dbi, crashBlobKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(v, crashBlob(last)) {
+			return fmt.Errorf("blob is not the one of transaction %d", last)
+		}
+
+		cur, err := txn.OpenCursor(dbi)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		var seq uint64
+		for k, v, err := cur.Get(nil, nil, First); ; k, v, err = cur.Get(nil, nil, Next) {
+			if IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if len(k) != 8 {
+				continue
+			}
+			seq++
+			if binary.BigEndian.Uint64(k) != seq || !bytes.Equal(v, crashItem(seq)) {
+				return fmt.Errorf("item %x: not the item of transaction %d", k, seq)
+			}
+		}
+		if seq != last {
+			return fmt.Errorf("%d items, last transaction is %d", seq, last)
+		}
+
+		_, err = txn.CheckPages()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return last
+}
+
+// openCrashRecovery opens the environment at path as it would be after a
+// system crash, i.e. at its latest steady meta page.
+func openCrashRecovery(t *testing.T, path string) *Env {
+	open := func(meta int) (*Env, error) {
+		env, err := NewEnv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := env.SetMaxDBs(1); err != nil {
+			t.Fatal(err)
+		}
+		if err := env.OpenForRecovery(path, meta, false); err != nil {
+			env.Close()
+			return nil, err
+		}
+		return env, nil
+	}
+
+	var info *EnvInfo
+	for meta := 0; info == nil; meta++ {
+		env, err := open(meta)
+		if err != nil {
+			if meta == len(EnvInfo{}.Metas)-1 {
+				t.Fatalf("no meta page can be opened: %v", err)
+			}
+			continue
+		}
+		info, err = env.Info()
+		env.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	steady := info.SteadyMeta()
+	if steady < 0 {
+		t.Fatalf("no steady meta page: %+v", info.Metas)
+	}
+	env, err := open(steady)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+// TestEnv_crash kills a writer process at random commit points and checks
+// that the acknowledged commits are found in the environment according to
+// its sync mode.
+//
+// After the writer is killed the environment is first opened as it would be
+// after a power loss: at the latest steady meta page, as if nothing written
+// since the last sync reached the disk.  The transactions acknowledged by a
+// forced sync must be present in every mode, and in Durable mode every
+// acknowledged commit must be.  The simulation relies on the signs of the meta
+// pages, so it cannot lose the unsynced meta pages of NoMetaSync, which are
+// written as steady.  Then the environment is opened normally, as
+// after a crash of the process alone, where every acknowledged commit must be
+// present whatever the mode.
+//
+// The environments are created in the directory named by MDBXGO_CRASH_DIR, if
+// set, which makes it possible to run the test on another file system.
+func TestEnv_crash(t *testing.T) {
+	modes := []struct {
+		name  string
+		flags uint
+	}{
+		{"Durable", Durable},
+		{"SafeNoSync", SafeNoSync},
+		{"NoMetaSync", NoMetaSync},
+	}
+	rounds := 8
+	if testing.Short() {
+		rounds = 3
+	}
+	seed := time.Now().UnixNano()
+	t.Logf("seed %d", seed)
+	rng := rand.New(rand.NewSource(seed))
+
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			path, err := ioutil.TempDir(os.Getenv(crashDirEnv), "mdb_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(path)
+
+			var acks crashAcks
+			for i := 0; i < rounds; i++ {
+				runCrashWriter(t, path, mode.flags, 1+rng.Intn(100), &acks)
+
+				env := openCrashRecovery(t, path)
+				steady := verifyCrash(t, env)
+				env.Close()
+				if steady < acks.synced {
+					t.Fatalf("round %d: synced transaction %d lost after a power loss, last is %d", i, acks.synced, steady)
+				}
+				if mode.flags == Durable && steady < acks.acked {
+					t.Fatalf("round %d: acknowledged transaction %d lost after a power loss, last is %d", i, acks.acked, steady)
+				}
+
+				env, err := NewEnv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := env.SetMaxDBs(1); err != nil {
+					t.Fatal(err)
+				}
+				if err := env.Open(path, mode.flags, 0644); err != nil {
+					t.Fatal(err)
+				}
+				last := verifyCrash(t, env)
+				env.Close()
+				// The commit in progress when the writer was killed may have
+				// completed without being acknowledged.
+				if last < acks.acked || last > acks.acked+1 {
+					t.Fatalf("round %d: last transaction %d, acknowledged %d", i, last, acks.acked)
+				}
+				if last < steady {
+					t.Fatalf("round %d: last transaction %d before steady transaction %d", i, last, steady)
+				}
+				acks.acked = last
+			}
+		})
+	}
+}
@@ -61,6 +61,17 @@ const (
 type Cursor struct {
 	txn *Txn
 	_c  *C.MDBX_cursor
+
+	// Scratch space reused by PutBatch to pack its items.
+	batch     []byte
+	batchLens []C.size_t
+
+	// Scratch space for the integer keys of GetUint64 and PutUint64.
+	ikey []byte
+
+	// Scratch space reused by GetReuse for the items it returns.
+	rkey []byte
+	rval []byte
 }
 
 func openCursor(txn *Txn, db DBI) (*Cursor, error) {
@@ -144,6 +155,9 @@ func (c *Cursor) DBI() DBI {
 //
 // See mdb_cursor_get.
 func (c *Cursor) Get(setkey, setval []byte, op uint) (key, val []byte, err error) {
+	if err := c.txn.check("mdbx_cursor_get"); err != nil {
+		return nil, nil, err
+	}
 	switch {
 	case len(setkey) == 0:
 		err = c.getVal0(op)
@@ -183,6 +197,43 @@ func (c *Cursor) Get(setkey, setval []byte, op uint) (key, val []byte, err error
 	return key, val, nil
 }
 
+// GetReuse is like Get, but copies the item into scratch buffers owned by c
+// instead of allocating new slices.  GetReuse always copies, whatever the
+// value of c.Txn().RawRead.  The returned slices are only valid until the next
+// call to GetReuse on c, which overwrites them.  Once the buffers have grown
+// to the size of the largest item GetReuse does not allocate.
+//
+// See mdb_cursor_get.
+func (c *Cursor) GetReuse(setkey, setval []byte, op uint) (key, val []byte, err error) {
+	if err := c.txn.check("mdbx_cursor_get"); err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case len(setkey) == 0:
+		err = c.getVal0(op)
+	case len(setval) == 0:
+		err = c.getVal1(setkey, op)
+	default:
+		err = c.getVal2(setkey, setval, op)
+	}
+	if err != nil {
+		*c.txn.key = C.MDBX_val{}
+		*c.txn.val = C.MDBX_val{}
+		return nil, nil, err
+	}
+
+	// See Get about the key returned by the Set op.
+	if op == Set {
+		c.rkey = append(c.rkey[:0], setkey...)
+	} else {
+		c.rkey = append(c.rkey[:0], getBytes(c.txn.key)...)
+	}
+	c.rval = append(c.rval[:0], getBytes(c.txn.val)...)
+	*c.txn.key = C.MDBX_val{}
+	*c.txn.val = C.MDBX_val{}
+	return c.rkey, c.rval, nil
+}
+
 // getVal0 retrieves items from the database without using given key or value
 // data for reference (Next, First, Last, etc).
 //
@@ -230,6 +281,9 @@ func (c *Cursor) putNilKey(flags uint) error {
 //
 // See mdb_cursor_put.
 func (c *Cursor) Put(key, val []byte, flags uint) error {
+	if err := c.txn.check("mdbx_cursor_put"); err != nil {
+		return err
+	}
 	if len(key) == 0 {
 		return c.putNilKey(flags)
 	}
@@ -250,6 +304,9 @@ func (c *Cursor) Put(key, val []byte, flags uint) error {
 // avoiding a memcopy.  The returned byte slice is only valid in txn's thread,
 // before it has terminated.
 func (c *Cursor) PutReserve(key []byte, n int, flags uint) ([]byte, error) {
+	if err := c.txn.check("mdbx_cursor_put"); err != nil {
+		return nil, err
+	}
 	if len(key) == 0 {
 		return nil, c.putNilKey(flags)
 	}
@@ -277,6 +334,9 @@ func (c *Cursor) PutReserve(key []byte, n int, flags uint) ([]byte, error) {
 //
 // See mdb_cursor_put.
 func (c *Cursor) PutMulti(key []byte, page []byte, stride int, flags uint) error {
+	if err := c.txn.check("mdbxgo_cursor_putmulti"); err != nil {
+		return err
+	}
 	if len(key) == 0 {
 		return c.putNilKey(flags)
 	}
@@ -294,10 +354,54 @@ func (c *Cursor) PutMulti(key []byte, page []byte, stride int, flags uint) error
 	return operrno("mdbxgo_cursor_putmulti", ret)
 }
 
+// PutBatch stores the items keys[i], vals[i] in the database, in order, using
+// a single cgo call instead of one per item.  Flags apply to every item.
+// PutBatch returns the number of items stored, which is less than len(keys)
+// only if an error is returned.  PutBatch panics if keys and vals do not have
+// the same length.
+//
+// See mdb_cursor_put.
+func (c *Cursor) PutBatch(keys, vals [][]byte, flags uint) (int, error) {
+	if len(keys) != len(vals) {
+		panic("incongruent arguments")
+	}
+	if err := c.txn.check("mdbx_cursor_put"); err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	data := c.batch[:0]
+	lens := c.batchLens[:0]
+	for i := range keys {
+		data = append(data, keys[i]...)
+		data = append(data, vals[i]...)
+		lens = append(lens, C.size_t(len(keys[i])), C.size_t(len(vals[i])))
+	}
+	// A trailing byte keeps data from being empty so it can be passed by
+	// pointer.
+	data = append(data, 0)
+	c.batch, c.batchLens = data, lens
+
+	var done C.size_t
+	ret := C.mdbxgo_cursor_put_batch(
+		c._c,
+		(*C.char)(unsafe.Pointer(&data[0])),
+		&lens[0], C.size_t(len(keys)),
+		C.MDBX_put_flags_t(flags),
+		&done,
+	)
+	return int(done), operrno("mdbx_cursor_put", ret)
+}
+
 // Del deletes the item referred to by the cursor from the database.
 //
 // See mdb_cursor_del.
 func (c *Cursor) Del(flags uint) error {
+	if err := c.txn.check("mdbx_cursor_del"); err != nil {
+		return err
+	}
 	ret := C.mdbx_cursor_del(c._c, C.MDBX_put_flags_t(flags))
 	return operrno("mdbx_cursor_del", ret)
 }
@@ -306,6 +410,9 @@ func (c *Cursor) Del(flags uint) error {
 //
 // See mdb_cursor_count.
 func (c *Cursor) Count() (uint64, error) {
+	if err := c.txn.check("mdbx_cursor_count"); err != nil {
+		return 0, err
+	}
 	var _size C.size_t
 	ret := C.mdbx_cursor_count(c._c, &_size)
 	if ret != success {
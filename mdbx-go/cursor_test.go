@@ -3,6 +3,7 @@ package mdbx
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"os"
@@ -162,6 +163,60 @@ func TestCursor_bytesBuffer(t *testing.T) {
 	}
 }
 
+func TestCursor_PutBatch(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		keys := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+		vals := [][]byte{[]byte("1"), {}, []byte("333")}
+		n, err := cur.PutBatch(keys, vals, 0)
+		if err != nil {
+			return err
+		}
+		if n != 3 {
+			t.Errorf("unexpected count: %d (!= 3)", n)
+		}
+		for i, k := range keys {
+			v, err := txn.Get(db, k)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(v, vals[i]) {
+				t.Errorf("unexpected value for %q: %q (!= %q)", k, v, vals[i])
+			}
+		}
+
+		// the batch stops at the first failure.
+		keys = [][]byte{[]byte("d"), []byte("b"), []byte("e")}
+		n, err = cur.PutBatch(keys, vals, NoOverwrite)
+		if !IsErrno(err, KeyExist) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if n != 1 {
+			t.Errorf("unexpected count: %d (!= 1)", n)
+		}
+		_, err = txn.Get(db, []byte("e"))
+		if !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
 func TestCursor_PutReserve(t *testing.T) {
 	env := setup(t)
 	defer clean(env, t)
@@ -1044,3 +1099,110 @@ func BenchmarkCursor_Renew(b *testing.B) {
 		return nil
 	})
 }
+
+func TestCursor_GetReuse(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		for _, k := range []string{"a", "b", "c"} {
+			if err := txn.Put(db, []byte(k), []byte(k+"-value"), 0); err != nil {
+				return err
+			}
+		}
+
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		k, v, err := cur.GetReuse([]byte("b"), nil, Set)
+		if err != nil {
+			return err
+		}
+		if string(k) != "b" || string(v) != "b-value" {
+			t.Errorf("unexpected item: %q %q", k, v)
+		}
+		k, v, err = cur.GetReuse(nil, nil, Next)
+		if err != nil {
+			return err
+		}
+		if string(k) != "c" || string(v) != "c-value" {
+			t.Errorf("unexpected item: %q %q", k, v)
+		}
+		if _, _, err := cur.GetReuse(nil, nil, Next); !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		allocs := testing.AllocsPerRun(100, func() {
+			_, _, err = cur.GetReuse(nil, nil, First)
+			if err == nil {
+				_, _, err = cur.GetReuse(nil, nil, Next)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if allocs != 0 {
+			t.Errorf("unexpected allocations per GetReuse: %v", allocs)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkCursor_GetReuse(b *testing.B) {
+	env := setup(b)
+	defer clean(env, b)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) (err error) {
+		for i := 0; i < 1000; i++ {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			err = txn.Put(db, k[:], bytes.Repeat(k[:], 16), Append)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) (err error) {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _, err = cur.GetReuse(nil, nil, Next)
+			if IsNotFound(err) {
+				_, _, err = cur.GetReuse(nil, nil, First)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		b.StopTimer()
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,150 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include <stdio.h>
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"bytes"
+)
+
+// DeleteRange deletes the items of database dbi with keys k such that
+// from <= k < to, according to the comparison function of the database, and
+// returns the number of items removed.  A nil from or to leaves the range
+// unbounded on that side.  In a DupSort database every duplicate of a key in
+// the range is removed and counted.
+//
+// Keys are read directly from the memory map and never copied, but every page
+// of the range is dirtied by txn.  Ranges too large for a single transaction
+// are deleted by Splitter.DeleteRange, which commits as it goes.
+func (txn *Txn) DeleteRange(dbi DBI, from, to []byte) (int, error) {
+	n, _, err := txn.deleteRange(dbi, from, to, nil, 0)
+	return n, err
+}
+
+// DeletePrefix deletes the items of database dbi with keys beginning with
+// prefix and returns the number of items removed.  See DeleteRange.
+//
+// DeletePrefix is only meaningful for databases using the default,
+// lexicographic, key order.
+func (txn *Txn) DeletePrefix(dbi DBI, prefix []byte) (int, error) {
+	n, _, err := txn.deleteRange(dbi, prefix, nil, prefix, 0)
+	return n, err
+}
+
+// deleteRange deletes the keys of the range starting at from, at most max of
+// them unless max is 0.  If it stops at the limit next is a copy of the first
+// key left in the range, otherwise it is nil.
+func (txn *Txn) deleteRange(dbi DBI, from, to, prefix []byte, max int) (n int, next []byte, err error) {
+	flags, err := txn.Flags(dbi)
+	if err != nil {
+		return 0, nil, err
+	}
+	cur, err := txn.OpenCursor(dbi)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cur.Close()
+	defer func() {
+		*txn.key = C.MDBX_val{}
+		*txn.val = C.MDBX_val{}
+	}()
+
+	var delFlags uint
+	if flags&DupSort != 0 {
+		delFlags = AllDups
+	}
+
+	if len(from) == 0 {
+		err = cur.getVal0(First)
+	} else {
+		err = cur.getVal1(from, SetRange)
+	}
+	for keys := 0; err == nil; err = cur.getVal0(NextNoDup) {
+		var key []byte
+		if txn.key.iov_len > 0 {
+			key = getBytes(txn.key)
+		}
+		if to != nil && txn.Cmp(dbi, key, to) >= 0 {
+			return n, nil, nil
+		}
+		if prefix != nil && !bytes.HasPrefix(key, prefix) {
+			return n, nil, nil
+		}
+		if max > 0 && keys == max {
+			return n, append([]byte{}, key...), nil
+		}
+
+		count := uint64(1)
+		if delFlags&AllDups != 0 {
+			count, err = cur.Count()
+			if err != nil {
+				return n, nil, err
+			}
+		}
+		// The cursor is left on the item following the deleted key, which
+		// NextNoDup returns without moving.
+		if err = cur.Del(delFlags); err != nil {
+			return n, nil, err
+		}
+		n += int(count)
+		keys++
+	}
+	if IsNotFound(err) {
+		return n, nil, nil
+	}
+	return n, nil, err
+}
+
+// deleteStep is the number of keys deleted by each step of
+// Splitter.DeleteRange, after which the space used by the transaction is
+// checked.
+const deleteStep = 256
+
+// DeleteRange deletes the items of database dbi with keys in [from, to) like
+// Txn.DeleteRange, over as many transactions as needed to keep each of them
+// within the limits of s, and returns the number of items removed.  Each
+// transaction deletes whole keys, in a DupSort database all the duplicates of
+// a key are removed by the same transaction.
+//
+// The items deleted by the transactions committed before an error are not
+// restored, and are not included in the count.  With a ProgressKey set, a
+// later call resumes the deletion from the last commit.
+func (s *Splitter) DeleteRange(dbi DBI, from, to []byte) (int, error) {
+	return s.deleteRange(dbi, from, to, nil)
+}
+
+// DeletePrefix deletes the items of database dbi with keys beginning with
+// prefix like Txn.DeletePrefix, over as many transactions as needed.  See
+// DeleteRange.
+func (s *Splitter) DeletePrefix(dbi DBI, prefix []byte) (int, error) {
+	return s.deleteRange(dbi, prefix, nil, prefix)
+}
+
+func (s *Splitter) deleteRange(dbi DBI, from, to, prefix []byte) (int, error) {
+	// Deletions are counted once their transaction is committed, a
+	// transaction aborted for TxnFull is run again.
+	var n, pending int
+	var last *Txn
+	c := *s
+	c.OnCommit = func(pos []byte) {
+		n += pending
+		pending = 0
+		if s.OnCommit != nil {
+			s.OnCommit(pos)
+		}
+	}
+	err := c.Run(from, func(txn *Txn, pos []byte) ([]byte, error) {
+		if txn != last {
+			last = txn
+			pending = 0
+		}
+		deleted, next, err := txn.deleteRange(dbi, pos, to, prefix, deleteStep)
+		pending += deleted
+		return next, err
+	})
+	return n, err
+}
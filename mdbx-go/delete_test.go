@@ -0,0 +1,188 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestTxn_DeleteRange(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "delrange", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(i int) []byte {
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], uint64(i))
+		return k[:]
+	}
+
+	// enough items to span many pages.
+	const n = 10000
+	err = env.Update(func(txn *Txn) error {
+		for i := 0; i < n; i++ {
+			if err := txn.Put(db, key(i), make([]byte, 64), Append); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		from, to []byte
+		deleted  int
+		remain   uint64
+	}{
+		{key(100), key(9000), 8900, n - 8900},
+		{key(100), key(9000), 0, n - 8900},
+		{key(9500), nil, 500, n - 9400},
+		{nil, key(50), 50, n - 9450},
+		{nil, nil, 550, 0},
+	} {
+		err = env.Update(func(txn *Txn) error {
+			deleted, err := txn.DeleteRange(db, test.from, test.to)
+			if err != nil {
+				return err
+			}
+			if deleted != test.deleted {
+				t.Errorf("[%x, %x): unexpected deleted count: %d (!= %d)", test.from, test.to, deleted, test.deleted)
+			}
+			stat, err := txn.StatDBI(db)
+			if err != nil {
+				return err
+			}
+			if stat.Entries != test.remain {
+				t.Errorf("[%x, %x): unexpected entries: %d (!= %d)", test.from, test.to, stat.Entries, test.remain)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTxn_DeletePrefix(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "delprefix", Create|DupSort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		for _, p := range []string{"a", "b", "bb", "c"} {
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 4; j++ {
+					k := fmt.Sprintf("%s/%d", p, i)
+					if err := txn.Put(db, []byte(k), []byte{byte(j)}, 0); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.Update(func(txn *Txn) error {
+		deleted, err := txn.DeletePrefix(db, []byte("b/"))
+		if err != nil {
+			return err
+		}
+		if deleted != 12 {
+			t.Errorf("unexpected deleted count: %d (!= 12)", deleted)
+		}
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != 36 {
+			t.Errorf("unexpected entries: %d (!= 36)", stat.Entries)
+		}
+		_, err = txn.Get(db, []byte("bb/0"))
+		return err
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitter_DeleteRange(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	if err := env.SetGeometry(-1, -1, 256<<20, -1, -1, -1); err != nil {
+		t.Fatal(err)
+	}
+	// a dirty page limit well below the pages of the range.
+	if err := env.SetOption(OptTxnDpLimit, 256); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openDBI(env, "delrange", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSplitter(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 50000
+	if err := s.Run(nil, splitStep(db, n)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.LastPNO < 2*256 {
+		t.Fatalf("database too small: %d pages", info.LastPNO)
+	}
+
+	// emptied pages are freed as the range is deleted, which keeps the
+	// dirty space low, a smaller limit makes it span several transactions.
+	s.MaxDirty = 16 << 10
+	var commits int
+	s.OnCommit = func(pos []byte) { commits++ }
+	var from [8]byte
+	binary.BigEndian.PutUint64(from[:], 100)
+	deleted, err := s.DeleteRange(db, from[:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != n-100 {
+		t.Errorf("unexpected deleted count: %d (!= %d)", deleted, n-100)
+	}
+	if commits < 2 {
+		t.Errorf("expected several commits, got %d", commits)
+	}
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != 100 {
+			t.Errorf("unexpected entries: %d (!= 100)", stat.Entries)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err = s.DeletePrefix(db, []byte{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 100 {
+		t.Errorf("unexpected prefix deleted count: %d (!= 100)", deleted)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -27,7 +28,7 @@ const (
 	LifoReclaim = C.MDBX_LIFORECLAIM
 	//FixedMap    = C.MDBX_FIXEDMAP   // Danger zone. Map memory at a fixed address.
 	NoSubdir   = C.MDBX_NOSUBDIR // Argument to Open is a file, not a directory.
-	Accede     = C.MDBX_ACCEDE
+	Accede     = C.MDBX_ACCEDE   // Adopt the mode flags of the processes already using the environment.
 	Coalesce   = C.MDBX_COALESCE
 	Readonly   = C.MDBX_RDONLY     // Used in several functions to denote an object as readonly.
 	WriteMap   = C.MDBX_WRITEMAP   // Use a writable memory map.
@@ -38,7 +39,7 @@ const (
 	//NoLock      = C.MDBX_NOLOCK     // Danger zone. LMDB does not use any locks.
 	NoReadahead = C.MDBX_NORDAHEAD // Disable readahead. Requires OS support.
 	NoMemInit   = C.MDBX_NOMEMINIT // Disable LMDB memory initialization.
-	Exclusive   = C.MDBX_EXCLUSIVE // Disable LMDB memory initialization.
+	Exclusive   = C.MDBX_EXCLUSIVE // Fail with Busy if another process uses the environment.
 )
 
 const (
@@ -112,6 +113,19 @@ type DBI C.MDBX_dbi
 //
 // See MDBX_env.
 type Env struct {
+	// MaxBatchSize is the maximum number of calls combined into one batch by
+	// Batch.  If MaxBatchSize is zero or negative batching is disabled and
+	// every call runs in its own transaction.
+	//
+	// MaxBatchSize must not be changed concurrently with calls to Batch.
+	MaxBatchSize int
+
+	// MaxBatchDelay is the maximum time a batch waits for more calls before
+	// it is run.
+	//
+	// MaxBatchDelay must not be changed concurrently with calls to Batch.
+	MaxBatchDelay time.Duration
+
 	_env *C.MDBX_env
 
 	// closeLock is used to allow the Txn finalizer to check if the Env has
@@ -120,13 +134,26 @@ type Env struct {
 
 	ckey *C.MDBX_val
 	cval *C.MDBX_val
+
+	// threadBound is set if env was opened without NoTLS, then read
+	// transactions must stay on the thread which began them.
+	threadBound bool
+
+	writerOnce sync.Once
+	writer     *Writer
+
+	batchMu sync.Mutex
+	batch   *batch
 }
 
 // NewEnv allocates and initializes a new Env.
 //
 // See mdbx_env_create.
 func NewEnv() (*Env, error) {
-	env := new(Env)
+	env := &Env{
+		MaxBatchSize:  DefaultMaxBatchSize,
+		MaxBatchDelay: DefaultMaxBatchDelay,
+	}
 	ret := C.mdbx_env_create(&env._env)
 	if ret != success {
 		return nil, operrno("mdbx_env_create", ret)
@@ -149,6 +176,10 @@ func (env *Env) Open(path string, flags uint, mode os.FileMode) error {
 	return operrno("mdbx_env_open", ret)
 }
 
+// ErrLagExceeded is returned by Env.ViewMaxLag when a read transaction kept
+// falling behind its lag limit and ran out of retries.
+var ErrLagExceeded = errors.New("read transaction lag exceeded its limit")
+
 var errNotOpen = errors.New("enivornment is not open")
 var errNegSize = errors.New("negative size")
 
@@ -216,11 +247,38 @@ func (env *Env) ReaderCheck() (int, error) {
 	return int(_dead), operrno("mdbx_reader_check", ret)
 }
 
+// OpenedByOtherProcess reports whether another process has the environment
+// open.  Environments opened by the calling process are not taken into
+// account, nor are processes which are killed, so OpenedByOtherProcess can be
+// used to decide whether a Copy or an operation requiring Exclusive mode
+// may be attempted.  The result is a snapshot, another process may open or
+// close the environment right after it was taken.  OpenedByOtherProcess always
+// returns false for an environment opened with Exclusive.
+//
+// OpenedByOtherProcess inspects the file locks held on the data file by the
+// processes using the environment and is not supported on Windows.
+func (env *Env) OpenedByOtherProcess() (bool, error) {
+	var shared C.int
+	ret := C.mdbxgo_env_shared(env._env, &shared)
+	if ret != success {
+		return false, operrno("mdbxgo_env_shared", ret)
+	}
+	return shared != 0, nil
+}
+
 func (env *Env) close() bool {
 	if env._env == nil {
 		return false
 	}
 
+	// The writer must finish its queue while the environment is still open.
+	// If it was never started a stopped one is installed, which fails the
+	// operations submitted after Close.
+	env.writerOnce.Do(func() {
+		env.writer = &Writer{env: env, closed: true}
+	})
+	env.writer.close()
+
 	env.closeLock.Lock()
 	C.mdbx_env_close(env._env)
 	env._env = nil
@@ -323,6 +381,7 @@ func (env *Env) Stat() (*Stat, error) {
 //
 // See MDBX_envinfo.
 type EnvInfo struct {
+	Geo                            EnvInfoGeo
 	MapSize                        int64 // Size of the data memory map
 	LastPNO                        int64 // ID of the last used page
 	LastTxnID                      int64 // ID of the last committed transaction
@@ -335,8 +394,45 @@ type EnvInfo struct {
 	AutosyncPeriodSeconds16dot16   uint  //
 	SinceReaderCheckSeconds16dot16 uint  //
 	Flags                          uint  //
+
+	// Metas describes the three meta pages of the database, the latest of
+	// which is the last committed transaction.
+	Metas [3]EnvInfoMeta
+}
+
+// EnvInfoMeta describes a meta page of the database.
+type EnvInfoMeta struct {
+	TxnID int64 // ID of the transaction which wrote the meta page
+	// Steady is true if the transaction was synced to disk.  After a system
+	// crash the database is rolled back to the latest steady meta page.
+	Steady bool
+}
+
+// SteadyMeta returns the index in Metas of the latest steady meta page, or
+// -1 if no meta page is steady.
+func (info *EnvInfo) SteadyMeta() int {
+	steady := -1
+	for i, m := range info.Metas {
+		if m.Steady && (steady < 0 || m.TxnID > info.Metas[steady].TxnID) {
+			steady = i
+		}
+	}
+	return steady
+}
+
+// EnvInfoGeo describes the geometry of the database file in bytes.  See
+// Env.SetGeometry.
+type EnvInfoGeo struct {
+	Lower   uint64 // Lower limit for datafile size
+	Upper   uint64 // Upper limit for datafile size
+	Current uint64 // Current datafile size
+	Shrink  uint64 // Shrink threshold for datafile
+	Grow    uint64 // Growth step for datafile
 }
 
+// dataSignWeak is the largest data sign of a meta page which is not steady.
+const dataSignWeak = 1
+
 // Info returns information about the environment.
 //
 // See mdbx_env_info.
@@ -353,6 +449,13 @@ func (env *Env) Info() (*EnvInfo, error) {
 		return nil, operrno("mdbx_env_info", ret)
 	}
 	info := EnvInfo{
+		Geo: EnvInfoGeo{
+			Lower:   uint64(_info.mi_geo.lower),
+			Upper:   uint64(_info.mi_geo.upper),
+			Current: uint64(_info.mi_geo.current),
+			Shrink:  uint64(_info.mi_geo.shrink),
+			Grow:    uint64(_info.mi_geo.grow),
+		},
 		MapSize:        int64(_info.mi_mapsize),
 		LastPNO:        int64(_info.mi_last_pgno),
 		LastTxnID:      int64(_info.mi_recent_txnid),
@@ -366,6 +469,12 @@ func (env *Env) Info() (*EnvInfo, error) {
 		AutosyncPeriodSeconds16dot16:   uint(_info.mi_autosync_period_seconds16dot16),
 		SinceReaderCheckSeconds16dot16: uint(_info.mi_since_reader_check_seconds16dot16),
 		Flags:                          uint(_info.mi_mode),
+
+		Metas: [3]EnvInfoMeta{
+			{int64(_info.mi_meta0_txnid), _info.mi_meta0_sign > dataSignWeak},
+			{int64(_info.mi_meta1_txnid), _info.mi_meta1_sign > dataSignWeak},
+			{int64(_info.mi_meta2_txnid), _info.mi_meta2_sign > dataSignWeak},
+		},
 	}
 	return &info, nil
 }
@@ -445,22 +554,35 @@ func (env *Env) Path() (string, error) {
 //	return uint(pages), operrno("mdbx_env_get_maxreaders", ret)
 //}
 
-// SetMapSize sets the size of the environment memory map.
+// SetMapSize sets the size of the environment memory map.  The database file
+// gets a fixed size, use SetGeometry to let it grow and shrink.
 //
-// See mdbx_env_set_mapsize.
-//func (env *Env) SetMapSize(size int64) error {
-//	if size < 0 {
-//		return errNegSize
-//	}
-//	ret := C.mdbx_env_set_mapsize(env._env, C.size_t(size))
-//	return operrno("mdbx_env_set_mapsize", ret)
-//}
+// See mdbx_env_set_mapsize, which is deprecated in favor of
+// mdbx_env_set_geometry and is emulated the same way here.
+func (env *Env) SetMapSize(size int64) error {
+	if size < 0 {
+		return errNegSize
+	}
+	ret := C.mdbx_env_set_geometry(env._env,
+		C.intptr_t(size), C.intptr_t(size), C.intptr_t(size), -1, -1, -1)
+	return operrno("mdbx_env_set_geometry", ret)
+}
 
 func (env *Env) SetOption(option uint, value uint64) error {
 	ret := C.mdbx_env_set_option(env._env, C.MDBX_option_t(option), C.uint64_t(value))
 	return operrno("mdbx_env_set_option", ret)
 }
 
+// GetOption returns the value of an option set with SetOption, or its
+// default.
+//
+// See mdbx_env_get_option.
+func (env *Env) GetOption(option uint) (uint64, error) {
+	var value C.uint64_t
+	ret := C.mdbx_env_get_option(env._env, C.MDBX_option_t(option), &value)
+	return uint64(value), operrno("mdbx_env_get_option", ret)
+}
+
 func (env *Env) SetGeometry(sizeLower int, sizeNow int, sizeUpper int, growthStep int, shrinkThreshold int, pageSize int) error {
 	ret := C.mdbx_env_set_geometry(env._env,
 		C.intptr_t(sizeLower),
@@ -572,6 +694,47 @@ func (env *Env) View(fn TxnOp) error {
 	return env.run(false, Readonly, fn)
 }
 
+// maxLagRetries is the number of times ViewMaxLag restarts a read
+// transaction which was broken for lagging behind.
+const maxLagRetries = 8
+
+// ViewMaxLag behaves like View but keeps the transaction from holding back
+// page reclamation.  If more than maxLag transactions are committed while fn
+// is running the transaction is broken (see Txn.Break), so that the next
+// operation fn performs on it fails, and fn is retried with a fresh snapshot.
+// Because of this fn must be safe to call multiple times.
+//
+// The lag is polled every 50ms, so fn may run that long past the limit, and
+// breaking the transaction only takes effect at the next operation of fn on
+// it.  If fn is still falling behind after 8 retries ViewMaxLag returns
+// ErrLagExceeded.  A call to fn which returns nil is never retried, even if
+// the transaction was broken after its last operation.
+func (env *Env) ViewMaxLag(maxLag int, fn TxnOp) error {
+	for i := 0; i <= maxLagRetries; i++ {
+		broken, err := env.viewMaxLag(maxLag, fn)
+		if !broken {
+			return err
+		}
+	}
+	return ErrLagExceeded
+}
+
+func (env *Env) viewMaxLag(maxLag int, fn TxnOp) (broken bool, err error) {
+	txn, err := beginTxn(env, nil, Readonly)
+	if err != nil {
+		return false, err
+	}
+	defer txn.abort()
+
+	// There is nothing to commit in a readonly txn, aborting it is enough.
+	txn.managed = true
+	stop := txn.watchLag(maxLag)
+	defer func() {
+		broken = stop() && err != nil
+	}()
+	return false, fn(txn)
+}
+
 // Update calls fn with a writable transaction.  Update commits the transaction
 // if fn returns a nil error otherwise Update aborts the transaction and
 // returns the error.
@@ -586,8 +749,8 @@ func (env *Env) View(fn TxnOp) error {
 // where it isn't known if runtime.LockOSThread has been called.  In such
 // situations writes must either be done in a newly created goroutine which can
 // be safely locked, or through a worker goroutine that accepts updates to
-// apply and delivers transaction results using channels.  See the package
-// documentation and examples for more details.
+// apply and delivers transaction results using channels, such as the one
+// returned by Env.Writer.  See the package documentation for more details.
 //
 // Goroutines created by the operation fn must not use methods on the Txn
 // object that fn is passed.  Doing so would have undefined and unpredictable
@@ -607,8 +770,8 @@ func (env *Env) Update(fn TxnOp) error {
 // where it isn't known if runtime.LockOSThread has been called.  In such
 // situations writes must either be done in a newly created goroutine which can
 // be safely locked, or through a worker goroutine that accepts updates to
-// apply and delivers transaction results using channels.  See the package
-// documentation and examples for more details.
+// apply and delivers transaction results using channels, such as the one
+// returned by Env.Writer.  See the package documentation for more details.
 //
 // Goroutines created by the operation fn must not use methods on the Txn
 // object that fn is passed.  Doing so would have undefined and unpredictable
@@ -621,7 +784,7 @@ func (env *Env) UpdateLocked(fn TxnOp) error {
 }
 
 func (env *Env) run(lock bool, flags uint, fn TxnOp) error {
-	if lock {
+	if lock || env.threadBound && flags&Readonly != 0 {
 		runtime.LockOSThread()
 		defer runtime.UnlockOSThread()
 	}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"syscall"
 	"testing"
+	"time"
 )
 
 func TestEnv_Path_notOpen(t *testing.T) {
@@ -521,6 +522,61 @@ func TestEnv_Sync(t *testing.T) {
 	}
 }
 
+func TestEnv_ViewMaxLag(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put := func(v string) error {
+		return env.Update(func(txn *Txn) (err error) {
+			return txn.Put(db, []byte("k"), []byte(v), 0)
+		})
+	}
+	if err = put("v0"); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	err = env.ViewMaxLag(1, func(txn *Txn) error {
+		calls++
+		if calls == 1 {
+			// fall behind by more than one transaction and wait for the
+			// watcher to notice.
+			errc := make(chan error)
+			go func() {
+				for _, v := range []string{"v1", "v2"} {
+					if err := put(v); err != nil {
+						errc <- err
+						return
+					}
+				}
+				errc <- nil
+			}()
+			if err := <-errc; err != nil {
+				return err
+			}
+			time.Sleep(4 * lagPollInterval)
+		}
+		v, err := txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v2" {
+			return fmt.Errorf("unexpected value: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if calls != 2 {
+		t.Errorf("unexpected number of calls: %d (!= %d)", calls, 2)
+	}
+}
+
 func setup(t T) *Env {
 	return setupFlags(t, 0)
 }
@@ -25,6 +25,12 @@ func (err *OpError) Error() string {
 	return err.Op + ": " + err.Errno.Error()
 }
 
+// Unwrap returns err.Errno, which allows errors.Is to match the Errno or the
+// context error wrapped by err.
+func (err *OpError) Unwrap() error {
+	return err.Errno
+}
+
 // Errno is an error type that represents the (unique) errno values defined by
 // LMDB.  Other errno values (such as EINVAL) are represented with type
 // syscall.Errno.  On Windows, LMDB return codes are translated into portable
@@ -68,8 +74,13 @@ const (
 	BadValSize      Errno = C.MDBX_BAD_VALSIZE
 	BadDBI          Errno = C.MDBX_BAD_DBI
 	Perm            Errno = C.MDBX_EPERM
+	KeyMismatch     Errno = C.MDBX_EKEYMISMATCH
+	Busy            Errno = C.MDBX_BUSY
 	//TLSFull       Errno = C.MDBX_TLS_FULL
-	//MapResized    Errno = C.MDBX_MAP_RESIZED
+
+	// MapResized is MDBX_UNABLE_EXTEND_MAPSIZE, which replaces the deprecated
+	// MDBX_MAP_RESIZED.
+	MapResized Errno = C.MDBX_UNABLE_EXTEND_MAPSIZE
 )
 
 // minimum and maximum values produced for the Errno type. syscall.Errnos of
@@ -107,11 +118,14 @@ func IsMapFull(err error) bool {
 	return IsErrno(err, MapFull)
 }
 
-// IsMapResized returns true if the environment has grown too large for the
-// current map after being resized by another process.
-//func IsMapResized(err error) bool {
-//	return IsErrno(err, MapResized)
-//}
+// IsMapResized returns true if mdbx was unable to extend the memory map of
+// the environment (MapResized, i.e. MDBX_UNABLE_EXTEND_MAPSIZE).  This happens
+// when a read transaction begins after another process grew the database
+// beyond the map size, in which case the environment must be reopened, or
+// when the map cannot be grown by a write transaction or Env.SetGeometry.
+func IsMapResized(err error) bool {
+	return IsErrno(err, MapResized)
+}
 
 // IsErrno returns true if err's errno is the given errno.
 func IsErrno(err error, errno Errno) bool {
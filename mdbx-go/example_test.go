@@ -0,0 +1,71 @@
+package mdbx_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/AskAlexSharov/inblocks_reproduce/mdbx-go"
+)
+
+// This example shows how goroutines which do not know whether they are locked
+// to an OS thread can write to an environment through its Writer.
+func ExampleEnv_Writer() {
+	path, err := ioutil.TempDir("", "mdbx_example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	env, err := mdbx.NewEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer env.Close()
+	if err = env.Open(path, 0, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	var dbi mdbx.DBI
+	w := env.Writer()
+	_, err = w.Update(context.Background(), func(txn *mdbx.Txn) (err error) {
+		dbi, err = txn.OpenRoot(0)
+		return err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Submit does not wait for the transaction, the returned future does.
+	done := make(chan error)
+	for _, k := range []string{"a", "b", "c"} {
+		k := k
+		go func() {
+			f := w.Submit(context.Background(), func(txn *mdbx.Txn) error {
+				return txn.Put(dbi, []byte(k), []byte("value of "+k), 0)
+			})
+			_, err := f.Wait()
+			done <- err
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-done; err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	err = env.View(func(txn *mdbx.Txn) error {
+		v, err := txn.Get(dbi, []byte("b"))
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(v))
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output: value of b
+}
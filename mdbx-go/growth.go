@@ -0,0 +1,115 @@
+package mdbx
+
+import (
+	"errors"
+	"math"
+	"runtime"
+)
+
+// MapGrowth is a policy for growing the database when a write transaction
+// fails with MapFull.  See Env.UpdateGrow.
+type MapGrowth struct {
+	// Step is the number of bytes added to the upper size limit of the
+	// database on each growth.  If Step is zero the limit is doubled.  Step
+	// must not be negative.
+	Step int64
+
+	// Ceiling is the largest upper size limit the policy may set.  It must
+	// be positive, a policy never growing the database is expressed by a
+	// Ceiling no larger than the current limit.  Ceilings beyond the address
+	// space of the platform are lowered to its largest int.
+	Ceiling int64
+
+	// OnGrow, if not nil, is called after each growth.
+	OnGrow func(ev MapGrowthEvent)
+}
+
+// MapGrowthEvent describes a growth of the database made by Env.UpdateGrow.
+type MapGrowthEvent struct {
+	OldUpper uint64 // Upper size limit before the growth
+	NewUpper uint64 // Upper size limit after the growth
+	Attempt  int    // Number of growths made for the same update
+}
+
+// UpdateGrow behaves like Update, except that when the transaction fails with
+// MapFull the upper size limit of the database is raised according to policy
+// and fn is run again in a new transaction.  Because of this fn may be called
+// more than once.  Once the limit has reached policy.Ceiling the MapFull
+// error is returned.
+//
+// The geometry is changed with SetGeometry, so the new limit applies to
+// other processes using the database as well.
+//
+// UpdateGrow returns an error without running fn if policy.Ceiling is not
+// positive or policy.Step is negative.
+func (env *Env) UpdateGrow(policy MapGrowth, fn TxnOp) error {
+	if policy.Ceiling <= 0 {
+		return errors.New("map growth ceiling must be positive")
+	}
+	if policy.Step < 0 {
+		return errors.New("map growth step must not be negative")
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	for attempt := 1; ; attempt++ {
+		err := env.UpdateLocked(fn)
+		if !IsMapFull(err) {
+			return err
+		}
+		ev, grown, gerr := env.grow(policy)
+		if gerr != nil {
+			return gerr
+		}
+		if !grown {
+			return err
+		}
+		ev.Attempt = attempt
+		if policy.OnGrow != nil {
+			policy.OnGrow(ev)
+		}
+	}
+}
+
+// grow raises the upper size limit of the database by one step of policy.
+// It reports false if the limit is already at the ceiling.
+func (env *Env) grow(policy MapGrowth) (ev MapGrowthEvent, grown bool, err error) {
+	info, err := env.Info()
+	if err != nil {
+		return ev, false, err
+	}
+	// SetGeometry takes an int, which on 32-bit platforms cannot hold every
+	// ceiling.
+	ceiling := uint64(policy.Ceiling)
+	if ceiling > math.MaxInt {
+		ceiling = math.MaxInt
+	}
+	upper := info.Geo.Upper
+	if upper >= ceiling {
+		return ev, false, nil
+	}
+	step := uint64(policy.Step)
+	if step == 0 {
+		step = upper
+	}
+	next := ceiling
+	if step < ceiling-upper {
+		next = upper + step
+	}
+	err = env.SetGeometry(-1, -1, int(next), -1, -1, -1)
+	if err != nil {
+		return ev, false, err
+	}
+
+	// The limit set by mdbx is rounded to its allocation granularity.
+	info, err = env.Info()
+	if err != nil {
+		return ev, false, err
+	}
+	if info.Geo.Upper <= upper {
+		return ev, false, nil
+	}
+	ev.OldUpper = upper
+	ev.NewUpper = info.Geo.Upper
+	return ev, true, nil
+}
@@ -0,0 +1,107 @@
+package mdbx
+
+import (
+	"testing"
+)
+
+func TestEnv_UpdateGrow(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	const upper = 1 << 20
+	err := env.SetGeometry(-1, -1, upper, 64<<10, -1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Geo.Upper != upper {
+		t.Fatalf("unexpected upper limit: %d (!= %d)", info.Geo.Upper, upper)
+	}
+
+	db, err := openDBI(env, "grow", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put := func(txn *Txn) error {
+		for i := 0; i < 1024; i++ {
+			k := []byte{byte(i >> 8), byte(i)}
+			if err := txn.Put(db, k, make([]byte, 4096), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// a ceiling which is too low.
+	var events []MapGrowthEvent
+	policy := MapGrowth{
+		Ceiling: 2 << 20,
+		OnGrow:  func(ev MapGrowthEvent) { events = append(events, ev) },
+	}
+	err = env.UpdateGrow(policy, put)
+	if !IsMapFull(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0].OldUpper != upper || events[0].NewUpper != 2<<20 {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	events = nil
+	policy.Step = 1 << 20
+	policy.Ceiling = 16 << 20
+	err = env.UpdateGrow(policy, put)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 {
+		t.Fatalf("no growth event")
+	}
+	for i, ev := range events {
+		if ev.Attempt != i+1 || ev.NewUpper != ev.OldUpper+1<<20 {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	}
+}
+
+func TestEnv_SetMapSize(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	const size = 4 << 20
+	if err := env.SetMapSize(size); err != nil {
+		t.Fatal(err)
+	}
+	info, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Geo.Upper != size || info.Geo.Current != size || info.MapSize != size {
+		t.Errorf("unexpected geometry: %+v (map size %d)", info.Geo, info.MapSize)
+	}
+	if err := env.SetMapSize(-1); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestEnv_UpdateGrow_invalid(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	for _, policy := range []MapGrowth{
+		{},
+		{Ceiling: -1},
+		{Ceiling: 1 << 30, Step: -1},
+	} {
+		called := false
+		err := env.UpdateGrow(policy, func(txn *Txn) error {
+			called = true
+			return nil
+		})
+		if err == nil || called {
+			t.Errorf("%+v: unexpected result: %v (called %t)", policy, err, called)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+)
+
+// nativeEndian is the byte order of the host, which mdbx uses to compare the
+// items of IntegerKey and IntegerDup databases.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// Uint64Bytes returns the 8 byte encoding of v used as key of IntegerKey
+// databases and as value of IntegerDup databases.  The encoding is native
+// endian, so a database using it can only be moved between hosts of the same
+// byte order.
+func Uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	nativeEndian.PutUint64(b, v)
+	return b
+}
+
+// Uint32Bytes returns the 4 byte encoding of v.  See Uint64Bytes.
+func Uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	nativeEndian.PutUint32(b, v)
+	return b
+}
+
+// BytesUint64 decodes an integer encoded by Uint64Bytes or Uint32Bytes.  An
+// error is returned if b is not 4 or 8 bytes long.
+func BytesUint64(b []byte) (uint64, error) {
+	switch len(b) {
+	case 8:
+		return nativeEndian.Uint64(b), nil
+	case 4:
+		return uint64(nativeEndian.Uint32(b)), nil
+	}
+	return 0, fmt.Errorf("invalid integer length %d", len(b))
+}
+
+// GetUint64 is like Get for an IntegerKey database with 8 byte keys, taking
+// and returning keys as integers.  Setkey is only used by ops which position
+// the cursor on a given key (Set, SetKey, SetRange, GetBoth and
+// GetBothRange), and setval only by GetBoth and GetBothRange.  The key is
+// decoded in place, without copying it out of the memory map.
+func (c *Cursor) GetUint64(setkey uint64, setval []byte, op uint) (key uint64, val []byte, err error) {
+	if err := c.txn.check("mdbx_cursor_get"); err != nil {
+		return 0, nil, err
+	}
+	switch op {
+	case Set, SetKey, SetRange, GetBoth, GetBothRange:
+		k := c.intKey(setkey)
+		if len(setval) == 0 {
+			err = c.getVal1(k, op)
+		} else {
+			err = c.getVal2(k, setval, op)
+		}
+	default:
+		err = c.getVal0(op)
+	}
+	if err == nil {
+		if op == Set {
+			key = setkey
+		} else {
+			key, err = BytesUint64(getBytes(c.txn.key))
+		}
+	}
+	if err == nil {
+		val = c.txn.bytes(c.txn.val)
+	}
+	*c.txn.key = C.MDBX_val{}
+	*c.txn.val = C.MDBX_val{}
+	if err != nil {
+		return 0, nil, err
+	}
+	return key, val, nil
+}
+
+// PutUint64 is like Put for an IntegerKey database with 8 byte keys.
+func (c *Cursor) PutUint64(key uint64, val []byte, flags uint) error {
+	return c.Put(c.intKey(key), val, flags)
+}
+
+// intKey encodes k into the scratch space of c and returns it.
+func (c *Cursor) intKey(k uint64) []byte {
+	if c.ikey == nil {
+		c.ikey = make([]byte, 8)
+	}
+	nativeEndian.PutUint64(c.ikey, k)
+	return c.ikey
+}
@@ -0,0 +1,223 @@
+package mdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+func TestCursor_GetUint64(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	keys := []uint64{1 << 40, 1, 256, 255, 1 << 32, 0}
+	var dbi DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		dbi, err = txn.OpenDBI("testdb", Create|IntegerKey, nil, nil)
+		if err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(dbi)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		for _, k := range keys {
+			if err := cur.PutUint64(k, Uint64Bytes(k), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(dbi)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		var prev uint64
+		for i := 0; ; i++ {
+			k, v, err := cur.GetUint64(0, nil, Next)
+			if IsNotFound(err) {
+				if i != len(keys) {
+					t.Errorf("unexpected number of keys: %d", i)
+				}
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if i > 0 && k <= prev {
+				t.Errorf("unexpected order: %d after %d", k, prev)
+			}
+			if x, err := BytesUint64(v); err != nil || x != k {
+				t.Errorf("unexpected value of %d: %x", k, v)
+			}
+			prev = k
+		}
+
+		k, _, err := cur.GetUint64(257, nil, SetRange)
+		if err != nil {
+			return err
+		}
+		if k != 1<<32 {
+			t.Errorf("unexpected key: %d", k)
+		}
+		if _, _, err := cur.GetUint64(2, nil, Set); !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIntegerDup(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) error {
+		dbi, err := txn.OpenDBI("testdb", Create|DupSort|DupFixed|IntegerDup, nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, v := range []uint32{1 << 16, 2, 1, 1 << 8} {
+			if err := txn.Put(dbi, []byte("k"), Uint32Bytes(v), 0); err != nil {
+				return err
+			}
+		}
+		cur, err := txn.OpenCursor(dbi)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		var vals []uint64
+		s := NewScanner(cur).Dups()
+		for s.Scan() {
+			v, err := BytesUint64(s.Val())
+			if err != nil {
+				return err
+			}
+			vals = append(vals, v)
+		}
+		if err := s.Err(); err != nil {
+			return err
+		}
+		for i := 1; i < len(vals); i++ {
+			if vals[i] <= vals[i-1] {
+				t.Errorf("unexpected order: %d", vals)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := BytesUint64([]byte{1, 2, 3}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+// BenchmarkIntegerKey compares random lookups of uint64 keys stored in
+// an IntegerKey database with the same keys stored big-endian in a database
+// using the default comparison function.
+func BenchmarkIntegerKey(b *testing.B) {
+	for _, bench := range []struct {
+		name  string
+		flags uint
+		put   func(cur *Cursor, k uint64, v []byte) error
+		get   func(cur *Cursor, k uint64) error
+	}{
+		{
+			name: "bigendian",
+			put: func(cur *Cursor, k uint64, v []byte) error {
+				var kb [8]byte
+				binary.BigEndian.PutUint64(kb[:], k)
+				return cur.Put(kb[:], v, 0)
+			},
+			get: func(cur *Cursor, k uint64) error {
+				var kb [8]byte
+				binary.BigEndian.PutUint64(kb[:], k)
+				_, _, err := cur.Get(kb[:], nil, Set)
+				return err
+			},
+		},
+		{
+			name:  "integerkey",
+			flags: IntegerKey,
+			put: func(cur *Cursor, k uint64, v []byte) error {
+				return cur.PutUint64(k, v, 0)
+			},
+			get: func(cur *Cursor, k uint64) error {
+				_, _, err := cur.GetUint64(k, nil, Set)
+				return err
+			},
+		},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			env := setup(b)
+			defer clean(env, b)
+			if err := env.SetGeometry(-1, -1, 1<<30, -1, -1, -1); err != nil {
+				b.Fatal(err)
+			}
+
+			const n = 100000
+			keys := make([]uint64, n)
+			rng := rand.New(rand.NewSource(1))
+			for i := range keys {
+				keys[i] = rng.Uint64()
+			}
+			val := bytes.Repeat([]byte{1}, 16)
+
+			var dbi DBI
+			err := env.Update(func(txn *Txn) (err error) {
+				dbi, err = txn.OpenDBI("bench", Create|bench.flags, nil, nil)
+				if err != nil {
+					return err
+				}
+				cur, err := txn.OpenCursor(dbi)
+				if err != nil {
+					return err
+				}
+				defer cur.Close()
+				for _, k := range keys {
+					if err := bench.put(cur, k, val); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			err = env.View(func(txn *Txn) error {
+				txn.RawRead = true
+				cur, err := txn.OpenCursor(dbi)
+				if err != nil {
+					return err
+				}
+				defer cur.Close()
+				for i := 0; i < b.N; i++ {
+					if err := bench.get(cur, keys[i%n]); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
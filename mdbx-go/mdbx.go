@@ -125,8 +125,10 @@ cannot determine whether the goroutine is locked to an OS thread.  This is a
 consequence of goroutine restrictions on write transactions and limitations in
 the runtime's thread locking implementation.  In such situations updates
 desired by the goroutine in question must be proxied by a goroutine with a
-known state (i.e.  "locked" or "unlocked").  See the included examples for more
-details about dealing with such situations.
+known state (i.e.  "locked" or "unlocked").  Env.Writer returns such a proxy,
+which runs updates submitted from any goroutine on a dedicated locked thread,
+and WorkerPool does the same for both views and updates.  See the Env.Writer
+example for more details about dealing with such situations.
 */
 package mdbx
 
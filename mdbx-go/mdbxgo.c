@@ -1,8 +1,14 @@
 /* lmdbgo.c
  * Helper utilities for github.com/bmatsuo/lmdb-go/lmdb
  * */
+#include <stdlib.h>
 #include <string.h>
 #include <stdio.h>
+#if !defined(_WIN32) && !defined(_WIN64)
+#include <errno.h>
+#include <fcntl.h>
+#include <unistd.h>
+#endif
 #include "_cgo_export.h"
 #include "mdbxgo.h"
 #include "dist/mdbx.h"
@@ -86,6 +92,43 @@ int mdbxgo_cursor_get2(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata, si
     return mdbx_cursor_get(cur, key, val, op);
 }
 
+int mdbxgo_cursor_put_batch(MDBX_cursor *cur, char *data, size_t *lens, size_t n, MDBX_put_flags_t flags, size_t *done) {
+    MDBX_val key, val;
+    size_t i;
+    int rc = MDBX_SUCCESS;
+    for (i = 0; i < n; i++) {
+        MDBXGO_SET_VAL(&key, lens[2*i], data);
+        data += lens[2*i];
+        MDBXGO_SET_VAL(&val, lens[2*i+1], data);
+        data += lens[2*i+1];
+        rc = mdbx_cursor_put(cur, &key, &val, flags);
+        if (rc != MDBX_SUCCESS)
+            break;
+    }
+    *done = i;
+    return rc;
+}
+
+int mdbxgo_get_many(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t *klens, size_t n, MDBX_val *vals, char *found) {
+    MDBX_val key;
+    size_t i;
+    int rc;
+    for (i = 0; i < n; i++) {
+        MDBXGO_SET_VAL(&key, klens[i], kdata);
+        kdata += klens[i];
+        rc = mdbx_get(txn, dbi, &key, &vals[i]);
+        if (rc == MDBX_NOTFOUND) {
+            MDBXGO_SET_VAL(&vals[i], 0, NULL);
+            found[i] = 0;
+            continue;
+        }
+        if (rc != MDBX_SUCCESS)
+            return rc;
+        found[i] = 1;
+    }
+    return MDBX_SUCCESS;
+}
+
 /* Compare two items lexically */
 //static int __hot cmp_lexical(const MDBX_val *a, const MDBX_val *b) {
 //  if (a->iov_len == b->iov_len)
@@ -97,28 +140,183 @@ int mdbxgo_cursor_get2(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata, si
 //  return likely(diff_data) ? diff_data : diff_len;
 //}
 
-static int mdbxgo_dup_cmp_exclude_suffix32(const MDBX_val *a, const MDBX_val *b) {
-	int diff;
-	ssize_t len_diff;
-	unsigned int len;
-	unsigned int lenA;
-	unsigned int lenB;
+/* Built-in comparators.  MDBX_cmp_func carries no context, so comparators
+ * taking a parameter are instantiated for every value from 0 to
+ * MDBXGO_CMP_PARAM_MAX and looked up in a table, larger values are bound to a
+ * parameter slot.
+ * */
+#define MDBXGO_CMP_PARAMS(X) \
+    X(0) X(1) X(2) X(3) X(4) X(5) X(6) X(7) X(8) X(9) X(10) X(11) X(12) \
+    X(13) X(14) X(15) X(16) X(17) X(18) X(19) X(20) X(21) X(22) X(23) X(24) X(25) \
+    X(26) X(27) X(28) X(29) X(30) X(31) X(32) X(33) X(34) X(35) X(36) X(37) X(38) \
+    X(39) X(40) X(41) X(42) X(43) X(44) X(45) X(46) X(47) X(48) X(49) X(50) X(51) \
+    X(52) X(53) X(54) X(55) X(56) X(57) X(58) X(59) X(60) X(61) X(62) X(63) X(64)
+
+static inline int mdbxgo_cmp_bytes(const void *a, size_t an, const void *b, size_t bn) {
+    size_t len = an < bn ? an : bn;
+    int diff = len ? memcmp(a, b, len) : 0;
+    if (diff)
+        return diff;
+    return an < bn ? -1 : an > bn;
+}
+
+/* exclude-suffix-N compares items lexically ignoring their last n bytes.  Items
+ * shorter than n are compared whole. */
+static inline int mdbxgo_cmp_exclude_suffix(const MDBX_val *a, const MDBX_val *b, size_t n) {
+    size_t an = a->iov_len >= n ? a->iov_len - n : a->iov_len;
+    size_t bn = b->iov_len >= n ? b->iov_len - n : b->iov_len;
+    return mdbxgo_cmp_bytes(a->iov_base, an, b->iov_base, bn);
+}
+
+/* prefix-K-reversed compares the first k bytes of items lexically, then the
+ * remainders in descending lexical order. */
+static inline int mdbxgo_cmp_prefix_reversed(const MDBX_val *a, const MDBX_val *b, size_t k) {
+    size_t ak = a->iov_len < k ? a->iov_len : k;
+    size_t bk = b->iov_len < k ? b->iov_len : k;
+    int diff = mdbxgo_cmp_bytes(a->iov_base, ak, b->iov_base, bk);
+    if (diff || ak < k)
+        return diff;
+    return mdbxgo_cmp_bytes((char *)b->iov_base + k, b->iov_len - k,
+                            (char *)a->iov_base + k, a->iov_len - k);
+}
+
+#define MDBXGO_DEFINE_CMP(n) \
+    static int mdbxgo_cmp_exclude_suffix_##n(const MDBX_val *a, const MDBX_val *b) { \
+        return mdbxgo_cmp_exclude_suffix(a, b, n); \
+    } \
+    static int mdbxgo_cmp_prefix_reversed_##n(const MDBX_val *a, const MDBX_val *b) { \
+        return mdbxgo_cmp_prefix_reversed(a, b, n); \
+    }
+MDBXGO_CMP_PARAMS(MDBXGO_DEFINE_CMP)
+
+#define MDBXGO_EXCLUDE_SUFFIX_ENTRY(n) mdbxgo_cmp_exclude_suffix_##n,
+static MDBX_cmp_func *const mdbxgo_cmp_exclude_suffix_table[] = {
+    MDBXGO_CMP_PARAMS(MDBXGO_EXCLUDE_SUFFIX_ENTRY)
+};
+
+#define MDBXGO_PREFIX_REVERSED_ENTRY(n) mdbxgo_cmp_prefix_reversed_##n,
+static MDBX_cmp_func *const mdbxgo_cmp_prefix_reversed_table[] = {
+    MDBXGO_CMP_PARAMS(MDBXGO_PREFIX_REVERSED_ENTRY)
+};
+
+/* Comparators taking a parameter larger than MDBXGO_CMP_PARAM_MAX are bound to
+ * one of the parameter slots below.  Each slot is a distinct C function reading
+ * its kind and parameter from mdbxgo_cmp_slots, which are set once by
+ * mdbxgo_param_cmp before the function is handed out and never change.
+ * */
+#define MDBXGO_CMP_PARAM_SLOTS(X) \
+    X(0) X(1) X(2) X(3) X(4) X(5) X(6) X(7) X(8) X(9) X(10) X(11) X(12) X(13) X(14) X(15) \
+    X(16) X(17) X(18) X(19) X(20) X(21) X(22) X(23) X(24) X(25) X(26) X(27) X(28) X(29) X(30) X(31)
+
+static struct {
+    int kind;
+    size_t param;
+} mdbxgo_cmp_slots[MDBXGO_CMP_PARAM_SLOT_MAX];
+
+static inline int mdbxgo_cmp_slot(size_t slot, const MDBX_val *a, const MDBX_val *b) {
+    if (mdbxgo_cmp_slots[slot].kind == MDBXGO_CMP_PREFIX_REVERSED)
+        return mdbxgo_cmp_prefix_reversed(a, b, mdbxgo_cmp_slots[slot].param);
+    return mdbxgo_cmp_exclude_suffix(a, b, mdbxgo_cmp_slots[slot].param);
+}
+
+#define MDBXGO_DEFINE_CMP_SLOT(n) \
+    static int mdbxgo_cmp_slot_##n(const MDBX_val *a, const MDBX_val *b) { \
+        return mdbxgo_cmp_slot(n, a, b); \
+    }
+MDBXGO_CMP_PARAM_SLOTS(MDBXGO_DEFINE_CMP_SLOT)
+
+#define MDBXGO_CMP_SLOT_ENTRY(n) mdbxgo_cmp_slot_##n,
+static MDBX_cmp_func *const mdbxgo_cmp_slot_table[] = {
+    MDBXGO_CMP_PARAM_SLOTS(MDBXGO_CMP_SLOT_ENTRY)
+};
+
+MDBX_cmp_func *mdbxgo_param_cmp(size_t slot, int kind, size_t param) {
+    if (slot >= MDBXGO_CMP_PARAM_SLOT_MAX)
+        return NULL;
+    if (kind != MDBXGO_CMP_EXCLUDE_SUFFIX && kind != MDBXGO_CMP_PREFIX_REVERSED)
+        return NULL;
+    mdbxgo_cmp_slots[slot].kind = kind;
+    mdbxgo_cmp_slots[slot].param = param;
+    return mdbxgo_cmp_slot_table[slot];
+}
+
+/* uint64be-prefix compares the first 8 bytes of items as big-endian integers,
+ * then the remainders lexically.  Items shorter than 8 bytes sort first. */
+static int mdbxgo_cmp_uint64be_prefix(const MDBX_val *a, const MDBX_val *b) {
+    const unsigned char *ap = a->iov_base, *bp = b->iov_base;
+    uint64_t ai = 0, bi = 0;
+    int i;
+
+    if (a->iov_len < 8 || b->iov_len < 8) {
+        if (a->iov_len >= 8)
+            return 1;
+        if (b->iov_len >= 8)
+            return -1;
+        return mdbxgo_cmp_bytes(ap, a->iov_len, bp, b->iov_len);
+    }
+    for (i = 0; i < 8; i++) {
+        ai = ai << 8 | ap[i];
+        bi = bi << 8 | bp[i];
+    }
+    if (ai != bi)
+        return ai < bi ? -1 : 1;
+    return mdbxgo_cmp_bytes(ap + 8, a->iov_len - 8, bp + 8, b->iov_len - 8);
+}
+
+/* reverse-lexical orders items in descending lexical order. */
+static int mdbxgo_cmp_reverse_lexical(const MDBX_val *a, const MDBX_val *b) {
+    return mdbxgo_cmp_bytes(b->iov_base, b->iov_len, a->iov_base, a->iov_len);
+}
+
+/* length-then-bytes orders shorter items first and items of the same length
+ * lexically. */
+static int mdbxgo_cmp_length_then_bytes(const MDBX_val *a, const MDBX_val *b) {
+    if (a->iov_len != b->iov_len)
+        return a->iov_len < b->iov_len ? -1 : 1;
+    return a->iov_len ? memcmp(a->iov_base, b->iov_base, a->iov_len) : 0;
+}
+
+/* Trampolines for comparators written in Go.  Each slot is a distinct C
+ * function forwarding to the mdbxgoCmpBridge function exported from
+ * cmp_go.go, which dispatches on the slot number.
+ * */
+#define MDBXGO_GOCMP_SLOTS(X) \
+    X(0) X(1) X(2) X(3) X(4) X(5) X(6) X(7) X(8) X(9) X(10) X(11) X(12) X(13) X(14) X(15) \
+    X(16) X(17) X(18) X(19) X(20) X(21) X(22) X(23) X(24) X(25) X(26) X(27) X(28) X(29) X(30) X(31)
+
+#define MDBXGO_DEFINE_GOCMP(n) \
+    static int mdbxgo_gocmp_##n(const MDBX_val *a, const MDBX_val *b) { \
+        return mdbxgoCmpBridge(n, (MDBX_val *)a, (MDBX_val *)b); \
+    }
+MDBXGO_GOCMP_SLOTS(MDBXGO_DEFINE_GOCMP)
 
-    lenA = a->iov_len >= 32 ? a->iov_len - 32 : a->iov_len;
-	lenB = b->iov_len >= 32 ? b->iov_len - 32 : b->iov_len;
-    len = lenA;
-	len_diff = (ssize_t) lenA - (ssize_t) lenB;
-	if (len_diff > 0) {
-		len = lenB;
-		len_diff = 1;
-	}
+#define MDBXGO_GOCMP_ENTRY(n) mdbxgo_gocmp_##n,
+static MDBX_cmp_func *const mdbxgo_gocmp_table[] = {
+    MDBXGO_GOCMP_SLOTS(MDBXGO_GOCMP_ENTRY)
+};
 
-	diff = memcmp(a->iov_base, b->iov_base, len);
-	return diff ? diff : len_diff<0 ? -1 : len_diff;
+MDBX_cmp_func *mdbxgo_gocmp(size_t slot) {
+    return slot < MDBXGO_GOCMP_SLOT_MAX ? mdbxgo_gocmp_table[slot] : NULL;
 }
 
 MDBX_cmp_func *mdbxgo_get_cmp_exclude_suffix32() {
-  return mdbxgo_dup_cmp_exclude_suffix32;
+  return mdbxgo_cmp_exclude_suffix_32;
+}
+
+MDBX_cmp_func *mdbxgo_builtin_cmp(int kind, size_t param) {
+    switch (kind) {
+    case MDBXGO_CMP_EXCLUDE_SUFFIX:
+        return param <= MDBXGO_CMP_PARAM_MAX ? mdbxgo_cmp_exclude_suffix_table[param] : NULL;
+    case MDBXGO_CMP_PREFIX_REVERSED:
+        return param <= MDBXGO_CMP_PARAM_MAX ? mdbxgo_cmp_prefix_reversed_table[param] : NULL;
+    case MDBXGO_CMP_UINT64BE_PREFIX:
+        return mdbxgo_cmp_uint64be_prefix;
+    case MDBXGO_CMP_REVERSE_LEXICAL:
+        return mdbxgo_cmp_reverse_lexical;
+    case MDBXGO_CMP_LENGTH_THEN_BYTES:
+        return mdbxgo_cmp_length_then_bytes;
+    }
+    return NULL;
 }
 
 //int mdbxgo_set_dupsort_cmp_exclude_suffix32(MDBX_txn *txn, MDBX_dbi dbi) {
@@ -164,5 +362,232 @@ MDBX_debug_func *mdbxgo_stderr_logger() {
   return mdbxgo_log_stderr;
 }
 
+/* Attribute support.  libmdbx ships the Nexenta attribute API behind
+ * MDBX_NEXENTA_ATTRS but the bundled implementation does not compile, so the
+ * same record layout is implemented here: a native-endian 64-bit attribute
+ * followed by the value data, written in place using MDBX_RESERVE.  Like the
+ * original these functions do not support MDBX_DUPSORT databases. */
+#define MDBXGO_ATTR_SIZE sizeof(uint64_t)
+
+/* An attribute-only value has no data to point at.  Go cannot slice a NULL
+ * pointer, so point such values at a static byte instead. */
+static char mdbxgo_attr_empty;
+
+static int mdbxgo_attr_peek(MDBX_val *val, uint64_t *attr) {
+    if (val->iov_len < MDBXGO_ATTR_SIZE)
+        return MDBX_INCOMPATIBLE;
+    memcpy(attr, val->iov_base, MDBXGO_ATTR_SIZE);
+    val->iov_len -= MDBXGO_ATTR_SIZE;
+    val->iov_base = val->iov_len ? (char *)val->iov_base + MDBXGO_ATTR_SIZE : &mdbxgo_attr_empty;
+    return MDBX_SUCCESS;
+}
+
+static void mdbxgo_attr_poke(MDBX_val *reserved, char *vdata, size_t vn, uint64_t attr) {
+    memcpy(reserved->iov_base, &attr, MDBXGO_ATTR_SIZE);
+    if (vn)
+        memcpy((char *)reserved->iov_base + MDBXGO_ATTR_SIZE, vdata, vn);
+}
+
+int mdbxgo_put_attr(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, uint64_t attr, MDBX_put_flags_t flags) {
+    MDBX_val key, reserve;
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    MDBXGO_SET_VAL(&reserve, vn + MDBXGO_ATTR_SIZE, NULL);
+    int rc = mdbx_put(txn, dbi, &key, &reserve, flags | MDBX_RESERVE);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    mdbxgo_attr_poke(&reserve, vdata, vn, attr);
+    return MDBX_SUCCESS;
+}
+
+int mdbxgo_get_attr(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_val *val, uint64_t *attr) {
+    MDBX_val key;
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    int rc = mdbx_get(txn, dbi, &key, val);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    return mdbxgo_attr_peek(val, attr);
+}
+
+int mdbxgo_set_attr(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, uint64_t attr) {
+    MDBX_val key, old;
+    uint64_t old_attr;
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    int rc = mdbx_get(txn, dbi, &key, &old);
+    if (rc == MDBX_NOTFOUND && vdata)
+        return mdbxgo_put_attr(txn, dbi, kdata, kn, vdata, vn, attr, 0);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    /* The old value is replaced outright, it does not need an attribute. */
+    if (vdata)
+        return mdbxgo_put_attr(txn, dbi, kdata, kn, vdata, vn, attr, MDBX_CURRENT);
+    rc = mdbxgo_attr_peek(&old, &old_attr);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    if (old_attr == attr)
+        return MDBX_SUCCESS;
+
+    /* The old value may live on a dirty page which is rewritten by the put
+     * below, so it has to be copied out first. */
+    char *tmp = NULL;
+    if (old.iov_len) {
+        tmp = malloc(old.iov_len);
+        if (!tmp)
+            return MDBX_ENOMEM;
+        memcpy(tmp, old.iov_base, old.iov_len);
+    }
+    rc = mdbxgo_put_attr(txn, dbi, kdata, kn, tmp, old.iov_len, attr, MDBX_CURRENT);
+    free(tmp);
+    return rc;
+}
+
+int mdbxgo_cursor_put_attr(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata, size_t vn, uint64_t attr, MDBX_put_flags_t flags) {
+    MDBX_val key, reserve;
+    MDBXGO_SET_VAL(&key, kn, kdata);
+    MDBXGO_SET_VAL(&reserve, vn + MDBXGO_ATTR_SIZE, NULL);
+    int rc = mdbx_cursor_put(cur, &key, &reserve, flags | MDBX_RESERVE);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    mdbxgo_attr_poke(&reserve, vdata, vn, attr);
+    return MDBX_SUCCESS;
+}
+
+int mdbxgo_cursor_get_attr(MDBX_cursor *cur, char *kdata, size_t kn, MDBX_val *key, MDBX_val *val, uint64_t *attr, MDBX_cursor_op op) {
+    if (kdata)
+        MDBXGO_SET_VAL(key, kn, kdata);
+    int rc = mdbx_cursor_get(cur, key, val, op);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    return mdbxgo_attr_peek(val, attr);
+}
+
+#if defined(_WIN32) || defined(_WIN64)
+int mdbxgo_env_shared(MDBX_env *env, int *shared) {
+    *shared = 0;
+    return MDBX_ENOSYS;
+}
+#else
+/* mdbxgo_probe_lock sets *locked if a lock held through another open file
+ * description conflicts with a write lock of the given range of fd. */
+static int mdbxgo_probe_lock(int fd, off_t start, off_t len, int *locked) {
+#ifdef F_OFD_GETLK
+    const int cmd = F_OFD_GETLK;
+#else
+    const int cmd = F_GETLK;
+#endif
+    struct flock lock;
+    for (;;) {
+        memset(&lock, 0, sizeof(lock));
+        lock.l_type = F_WRLCK;
+        lock.l_whence = SEEK_SET;
+        lock.l_start = start;
+        lock.l_len = len;
+        if (fcntl(fd, cmd, &lock) != -1)
+            break;
+        if (errno != EINTR)
+            return errno;
+    }
+    *locked = lock.l_type != F_UNLCK;
+    return MDBX_SUCCESS;
+}
+
+int mdbxgo_env_shared(MDBX_env *env, int *shared) {
+    *shared = 0;
+    unsigned flags;
+    int rc = mdbx_env_get_flags(env, &flags);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    if (flags & MDBX_EXCLUSIVE)
+        return MDBX_SUCCESS;
+    mdbx_filehandle_t fd;
+    rc = mdbx_env_get_fd(env, &fd);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+
+    /* Every process using the environment in cooperative mode locks the byte
+     * of the data file at the offset of its pid, the whole file is locked in
+     * exclusive mode and while the environment is being opened. */
+    const off_t pid = getpid();
+    rc = mdbxgo_probe_lock(fd, 0, pid, shared);
+    if (rc == MDBX_SUCCESS && !*shared)
+        rc = mdbxgo_probe_lock(fd, pid + 1, 0, shared);
+    return rc;
+}
+#endif
 
+typedef struct {
+    uint8_t *seen;
+    uint64_t npages;
+    uint64_t pages;
+    uint64_t bad;
+} mdbxgo_pgcheck;
 
+/* mdbxgo_pgcheck_mark marks the pages [pgno, pgno+number) as accounted for
+ * and returns the number of them which are out of the database or were
+ * already accounted for. */
+static uint64_t mdbxgo_pgcheck_mark(mdbxgo_pgcheck *check, uint64_t pgno, uint64_t number) {
+    uint64_t bad = 0;
+    for (uint64_t p = pgno; p < pgno + number; p++) {
+        if (p >= check->npages || check->seen[p / 8] & (1 << (p % 8))) {
+            bad++;
+            continue;
+        }
+        check->seen[p / 8] |= 1 << (p % 8);
+        check->pages++;
+    }
+    return bad;
+}
+
+static int mdbxgo_pgcheck_visitor(const uint64_t pgno, const unsigned number, void *const ctx, const int deep,
+        const char *const dbi, const size_t page_size, const MDBX_page_type_t type,
+        const MDBX_error_t err, const size_t nentries, const size_t payload_bytes,
+        const size_t header_bytes, const size_t unused_bytes) {
+    mdbxgo_pgcheck *check = ctx;
+    if (err != MDBX_SUCCESS)
+        check->bad++;
+    check->bad += mdbxgo_pgcheck_mark(check, pgno, number);
+    return MDBX_SUCCESS;
+}
+
+/* mdbxgo_pgcheck_gc marks the pages listed in the records of the garbage
+ * collector, each of which holds a page list whose first element is the
+ * number of pages in it. */
+static int mdbxgo_pgcheck_gc(MDBX_txn *txn, mdbxgo_pgcheck *check, uint64_t *gc) {
+    MDBX_cursor *cur;
+    int rc = mdbx_cursor_open(txn, 0 /* FREE_DBI */, &cur);
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    const uint64_t before = check->pages;
+    MDBX_val key, val;
+    while ((rc = mdbx_cursor_get(cur, &key, &val, MDBX_NEXT)) == MDBX_SUCCESS) {
+        const uint32_t *pnl = val.iov_base;
+        if (val.iov_len < sizeof(uint32_t) || val.iov_len < (pnl[0] + 1) * sizeof(uint32_t)) {
+            check->bad++;
+            continue;
+        }
+        for (uint32_t i = 1; i <= pnl[0]; i++)
+            check->bad += mdbxgo_pgcheck_mark(check, pnl[i], 1);
+    }
+    mdbx_cursor_close(cur);
+    *gc = check->pages - before;
+    return rc == MDBX_NOTFOUND ? MDBX_SUCCESS : rc;
+}
+
+int mdbxgo_check_pages(MDBX_txn *txn, uint64_t *used, uint64_t *gc, uint64_t *lost, uint64_t *bad) {
+    MDBX_envinfo info;
+    int rc = mdbx_env_info_ex(mdbx_txn_env(txn), txn, &info, sizeof(info));
+    if (rc != MDBX_SUCCESS)
+        return rc;
+    mdbxgo_pgcheck check = {.npages = info.mi_last_pgno + 1};
+    check.seen = calloc(check.npages / 8 + 1, 1);
+    if (!check.seen)
+        return MDBX_ENOMEM;
+    rc = mdbx_env_pgwalk(txn, mdbxgo_pgcheck_visitor, &check, false);
+    *used = check.pages;
+    *gc = 0;
+    if (rc == MDBX_SUCCESS)
+        rc = mdbxgo_pgcheck_gc(txn, &check, gc);
+    free(check.seen);
+    *lost = check.npages - check.pages;
+    *bad = check.bad;
+    return rc;
+}
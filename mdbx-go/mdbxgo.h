@@ -27,6 +27,15 @@ int mdbxgo_cursor_putmulti(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata
 int mdbxgo_cursor_get1(MDBX_cursor *cur, char *kdata, size_t kn, MDBX_val *key, MDBX_val *val, MDBX_cursor_op op);
 int mdbxgo_cursor_get2(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata, size_t vn, MDBX_val *key, MDBX_val *val, MDBX_cursor_op op);
 
+/* Batch proxies perform n operations per call.  Keys (and values) are packed
+ * back to back in data, their lengths are given by lens.  For
+ * mdbxgo_cursor_put_batch lens alternates key and value lengths and done
+ * receives the number of items stored.  For mdbxgo_get_many found[i] is set to
+ * 1 and vals[i] to the value of the i-th key if it exists.
+ * */
+int mdbxgo_cursor_put_batch(MDBX_cursor *cur, char *data, size_t *lens, size_t n, MDBX_put_flags_t flags, size_t *done);
+int mdbxgo_get_many(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t *klens, size_t n, MDBX_val *vals, char *found);
+
 /* ConstCString wraps a null-terminated (const char *) because Go's type system
  * does not represent the 'cosnt' qualifier directly on a function argument and
  * causes warnings to be emitted during linking.
@@ -46,6 +55,58 @@ int mdbxgo_dcmp(MDBX_txn *txn, MDBX_dbi dbi, char *adata, size_t an, char *bdata
 
 MDBX_cmp_func *mdbxgo_get_cmp_exclude_suffix32();
 
+/* mdbxgo_builtin_cmp returns the built-in comparator of the given kind, or NULL
+ * if param is out of range.  Param is the suffix length of
+ * MDBXGO_CMP_EXCLUDE_SUFFIX and the prefix length of MDBXGO_CMP_PREFIX_REVERSED,
+ * at most MDBXGO_CMP_PARAM_MAX, other kinds ignore it.
+ * */
+#define MDBXGO_CMP_PARAM_MAX 64
+enum {
+    MDBXGO_CMP_EXCLUDE_SUFFIX = 1,
+    MDBXGO_CMP_PREFIX_REVERSED,
+    MDBXGO_CMP_UINT64BE_PREFIX,
+    MDBXGO_CMP_REVERSE_LEXICAL,
+    MDBXGO_CMP_LENGTH_THEN_BYTES,
+};
+MDBX_cmp_func *mdbxgo_builtin_cmp(int kind, size_t param);
+
+/* mdbxgo_param_cmp binds the comparator of parameter slot to kind, which is
+ * MDBXGO_CMP_EXCLUDE_SUFFIX or MDBXGO_CMP_PREFIX_REVERSED, and param, and
+ * returns it.  It returns NULL if slot is not less than
+ * MDBXGO_CMP_PARAM_SLOT_MAX or kind takes no parameter.  A slot must not be
+ * bound again once its comparator is in use.
+ * */
+#define MDBXGO_CMP_PARAM_SLOT_MAX 32
+MDBX_cmp_func *mdbxgo_param_cmp(size_t slot, int kind, size_t param);
+
+/* mdbxgo_gocmp returns the comparator calling the Go comparator registered in
+ * slot, or NULL if slot is not less than MDBXGO_GOCMP_SLOT_MAX.
+ * */
+#define MDBXGO_GOCMP_SLOT_MAX 32
+MDBX_cmp_func *mdbxgo_gocmp(size_t slot);
+
 MDBX_debug_func *mdbxgo_stderr_logger();
 
+/* Proxy functions for the attribute API.  They follow the conventions of the
+ * get/put proxies above, a NULL vdata passed to mdbxgo_set_attr keeps the value
+ * already stored under the key.
+ * */
+int mdbxgo_put_attr(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, uint64_t attr, MDBX_put_flags_t flags);
+int mdbxgo_get_attr(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, MDBX_val *val, uint64_t *attr);
+int mdbxgo_set_attr(MDBX_txn *txn, MDBX_dbi dbi, char *kdata, size_t kn, char *vdata, size_t vn, uint64_t attr);
+int mdbxgo_cursor_put_attr(MDBX_cursor *cur, char *kdata, size_t kn, char *vdata, size_t vn, uint64_t attr, MDBX_put_flags_t flags);
+int mdbxgo_cursor_get_attr(MDBX_cursor *cur, char *kdata, size_t kn, MDBX_val *key, MDBX_val *val, uint64_t *attr, MDBX_cursor_op op);
+
+/* mdbxgo_env_shared sets *shared if a process other than the calling one has
+ * env open.
+ * */
+int mdbxgo_env_shared(MDBX_env *env, int *shared);
+
+/* mdbxgo_check_pages walks the b-trees of txn and the page lists of its
+ * garbage collector.  It counts the pages in use, the free pages, the pages
+ * which are neither (lost) and the problems found: damaged pages and pages
+ * which are out of the database or accounted for twice.
+ * */
+int mdbxgo_check_pages(MDBX_txn *txn, uint64_t *used, uint64_t *gc, uint64_t *lost, uint64_t *bad);
+
 #endif
@@ -0,0 +1,338 @@
+//go:build !windows
+// +build !windows
+
+package mdbx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// helperProcessEnv holds the path of the environment opened by a helper
+// process.  Test binaries run with it set execute TestHelperProcess only.
+const helperProcessEnv = "MDBXGO_HELPER_PROCESS"
+
+// helperProcess is a process opening the environment of a test.  It reads
+// commands from its stdin and answers each of them with a line on its stdout.
+type helperProcess struct {
+	t     *testing.T
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	out   *bufio.Scanner
+}
+
+// startHelper starts a helper process opening the environment at path with
+// flags and returns it with the line reporting the result of the open.
+func startHelper(t *testing.T, path string, flags uint) (*helperProcess, string) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperProcessEnv+"="+path)
+	cmd.Args = append(cmd.Args, "--", strconv.FormatUint(uint64(flags), 10))
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	p := &helperProcess{t: t, cmd: cmd, stdin: stdin, out: bufio.NewScanner(stdout)}
+	t.Cleanup(p.stop)
+	return p, p.line()
+}
+
+// do sends command to p and returns the answer.
+func (p *helperProcess) do(command string) string {
+	if _, err := fmt.Fprintln(p.stdin, command); err != nil {
+		p.t.Fatalf("helper: %v", err)
+	}
+	return p.line()
+}
+
+func (p *helperProcess) line() string {
+	if !p.out.Scan() {
+		p.t.Fatalf("helper: no output: %v", p.out.Err())
+	}
+	return p.out.Text()
+}
+
+// kill terminates p with SIGKILL, without giving it a chance to release the
+// environment.
+func (p *helperProcess) kill() {
+	if err := p.cmd.Process.Kill(); err != nil {
+		p.t.Fatal(err)
+	}
+	p.cmd.Wait()
+}
+
+// stop closes the stdin of p, which makes it close the environment and exit.
+func (p *helperProcess) stop() {
+	if p.cmd.ProcessState != nil {
+		return
+	}
+	p.stdin.Close()
+	p.cmd.Wait()
+}
+
+// TestHelperProcess is the body of the processes started by startHelper.  It
+// opens the environment and serves the following commands until its stdin is
+// closed.
+//
+//		read	begin a read transaction which is kept open
+//		info	report the flags and the geometry of the environment
+//
+// The open and every command are answered with "ok" followed by the flags
+// and the geometry of the environment, or with "error" and the error.
+func TestHelperProcess(t *testing.T) {
+	path := os.Getenv(helperProcessEnv)
+	if path == "" {
+		return
+	}
+	runtime.LockOSThread()
+	defer os.Exit(0)
+
+	flags, err := strconv.ParseUint(os.Args[len(os.Args)-1], 10, 0)
+	if err != nil {
+		fmt.Println("error", err)
+		return
+	}
+	env, err := NewEnv()
+	if err != nil {
+		fmt.Println("error", err)
+		return
+	}
+	defer env.Close()
+	if err := env.Open(path, uint(flags), 0644); err != nil {
+		fmt.Println("error", err)
+		return
+	}
+
+	var txns []*Txn
+	defer func() {
+		for _, txn := range txns {
+			txn.Abort()
+		}
+	}()
+	answer := func() {
+		info, err := env.Info()
+		if err != nil {
+			fmt.Println("error", err)
+			return
+		}
+		flags, err := env.Flags()
+		if err != nil {
+			fmt.Println("error", err)
+			return
+		}
+		fmt.Println("ok", flags, info.Geo.Current, info.Geo.Upper)
+	}
+	answer()
+
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		switch in.Text() {
+		case "read":
+			txn, err := env.BeginTxn(nil, Readonly)
+			if err != nil {
+				fmt.Println("error", err)
+				continue
+			}
+			txns = append(txns, txn)
+			answer()
+		case "info":
+			answer()
+		default:
+			fmt.Println("error unknown command", in.Text())
+		}
+	}
+}
+
+// helperAnswer parses an "ok" answer of a helper process.
+func helperAnswer(t *testing.T, line string) (flags uint, current, upper uint64) {
+	if _, err := fmt.Sscanf(line, "ok %d %d %d", &flags, &current, &upper); err != nil {
+		t.Fatalf("helper: %q", line)
+	}
+	return flags, current, upper
+}
+
+// openMultiproc opens a new environment for the multi-process tests.
+func openMultiproc(t *testing.T, flags uint) *Env {
+	path, err := ioutil.TempDir("", "mdb_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(path) })
+	env, err := NewEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Open(path, flags, 0644); err != nil {
+		env.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { env.Close() })
+	return env
+}
+
+func envPath(t *testing.T, env *Env) string {
+	path, err := env.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnv_OpenedByOtherProcess(t *testing.T) {
+	env := openMultiproc(t, 0)
+	path := envPath(t, env)
+
+	shared, err := env.OpenedByOtherProcess()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared {
+		t.Errorf("environment is reported open by another process")
+	}
+
+	p, line := startHelper(t, path, 0)
+	helperAnswer(t, line)
+	shared, err = env.OpenedByOtherProcess()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !shared {
+		t.Errorf("helper process is not detected")
+	}
+
+	p.stop()
+	shared, err = env.OpenedByOtherProcess()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared {
+		t.Errorf("environment is reported open after the helper exited")
+	}
+}
+
+func TestEnv_ReaderCheck_killed(t *testing.T) {
+	env := openMultiproc(t, 0)
+	p, line := startHelper(t, envPath(t, env), 0)
+	helperAnswer(t, line)
+	helperAnswer(t, p.do("read"))
+
+	info, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.NumReaders == 0 {
+		t.Fatalf("reader slot of the helper is not used")
+	}
+	dead, err := env.ReaderCheck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dead != 0 {
+		t.Errorf("reader of a live process cleared: %d", dead)
+	}
+
+	p.kill()
+	shared, err := env.OpenedByOtherProcess()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared {
+		t.Errorf("killed process is reported to have the environment open")
+	}
+	dead, err = env.ReaderCheck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dead != 1 {
+		t.Errorf("stale readers cleared: %d (!= 1)", dead)
+	}
+	dead, err = env.ReaderCheck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dead != 0 {
+		t.Errorf("stale readers cleared twice: %d", dead)
+	}
+}
+
+func TestEnv_Exclusive_otherProcess(t *testing.T) {
+	env := openMultiproc(t, 0)
+	path := envPath(t, env)
+
+	_, line := startHelper(t, path, Exclusive)
+	if !strings.HasPrefix(line, "error") || !strings.Contains(line, Busy.Error()) {
+		t.Errorf("exclusive open of a used environment: %q", line)
+	}
+
+	// The environment cannot be opened while another process uses it
+	// exclusively, the lock held by that process is reported.
+	env.Close()
+	p, line := startHelper(t, path, Exclusive)
+	helperAnswer(t, line)
+	env2, err := NewEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env2.Close()
+	err = env2.Open(path, 0, 0644)
+	if !IsErrnoSys(err, syscall.EAGAIN) && !IsErrnoSys(err, syscall.EACCES) {
+		t.Errorf("open of an exclusively used environment: %v", err)
+	}
+
+	p.stop()
+	if err := env2.Open(path, 0, 0644); err != nil {
+		t.Errorf("open after the exclusive user exited: %v", err)
+	}
+}
+
+func TestEnv_Accede_otherProcess(t *testing.T) {
+	env := openMultiproc(t, SafeNoSync|LifoReclaim)
+	path := envPath(t, env)
+
+	_, line := startHelper(t, path, 0)
+	if !strings.HasPrefix(line, "error") || !strings.Contains(line, Incompatible.Error()) {
+		t.Errorf("open with different mode flags: %q", line)
+	}
+
+	_, line = startHelper(t, path, Accede)
+	flags, _, _ := helperAnswer(t, line)
+	if flags&(SafeNoSync|LifoReclaim) != SafeNoSync|LifoReclaim {
+		t.Errorf("flags of the acceding process: %#x", flags)
+	}
+}
+
+func TestEnv_SetGeometry_otherProcess(t *testing.T) {
+	env := openMultiproc(t, 0)
+	if err := env.SetGeometry(-1, 1<<20, 16<<20, -1, -1, -1); err != nil {
+		t.Fatal(err)
+	}
+	p, line := startHelper(t, envPath(t, env), 0)
+	_, current, upper := helperAnswer(t, line)
+	if current != 1<<20 || upper != 16<<20 {
+		t.Errorf("geometry seen by the helper: %d/%d", current, upper)
+	}
+
+	if err := env.SetGeometry(-1, 4<<20, 64<<20, -1, -1, -1); err != nil {
+		t.Fatal(err)
+	}
+	_, current, upper = helperAnswer(t, p.do("info"))
+	if current != 4<<20 || upper != 64<<20 {
+		t.Errorf("geometry seen by the helper: %d/%d", current, upper)
+	}
+}
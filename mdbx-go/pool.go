@@ -0,0 +1,190 @@
+package mdbx
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// TxnPool recycles readonly transactions, and their cursors (see
+// Txn.Cursor), across calls to View.  A transaction returned to the pool is
+// reset (see Txn.Reset) and renewed when it is taken again, which is much
+// cheaper than beginning a new one and allocates nothing.
+//
+// A reset transaction keeps its slot in the reader table, so the pool bounds
+// the number of transactions it owns, in use or idle.  Once they are all in
+// use View waits for one to be returned rather than failing with ReadersFull.
+//
+// A TxnPool must be closed before its environment.
+type TxnPool struct {
+	env    *Env
+	sem    chan struct{}
+	mu     sync.Mutex
+	idle   []*Txn
+	closed bool
+
+	hits        uint64
+	misses      uint64
+	renewErrors uint64
+}
+
+// TxnPoolStats reports the activity of a TxnPool.
+type TxnPoolStats struct {
+	Hits        uint64 // Transactions renewed from the pool
+	Misses      uint64 // Transactions begun because the pool was empty
+	RenewErrors uint64 // Pooled transactions discarded because Renew failed
+	Idle        int    // Transactions currently in the pool
+}
+
+// HitRate returns the fraction of View calls served by a pooled transaction.
+func (s TxnPoolStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// NewTxnPool returns a pool of at most size readonly transactions of env.  If
+// size is zero or negative it defaults to half the readers of env (see
+// Env.MaxReaders), and it is capped to the number of readers, keeping in mind
+// that transactions begun outside the pool need readers as well.
+func NewTxnPool(env *Env, size int) (*TxnPool, error) {
+	max, err := env.MaxReaders()
+	if err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		size = max / 2
+	}
+	if size > max {
+		size = max
+	}
+	if size < 1 {
+		size = 1
+	}
+	return &TxnPool{env: env, sem: make(chan struct{}, size)}, nil
+}
+
+// View behaves like Env.View, using a transaction from the pool.
+func (p *TxnPool) View(fn TxnOp) error {
+	txn, err := p.get()
+	if err != nil {
+		return err
+	}
+	return p.run(txn, fn)
+}
+
+// ViewCursor behaves like View, passing fn the cursor on dbi of the
+// transaction (see Txn.Cursor), which is kept with the transaction when it
+// returns to the pool.
+func (p *TxnPool) ViewCursor(dbi DBI, fn func(cur *Cursor) error) error {
+	return p.View(func(txn *Txn) error {
+		cur, err := txn.Cursor(dbi)
+		if err != nil {
+			return err
+		}
+		return fn(cur)
+	})
+}
+
+func (p *TxnPool) run(txn *Txn, fn TxnOp) error {
+	recycle := false
+	defer func() {
+		if recycle {
+			p.put(txn)
+		} else {
+			p.discard(txn)
+		}
+	}()
+
+	txn.managed = true
+	err := fn(txn)
+	txn.managed = false
+	txn.RawRead = false
+	txn.AutoRawRead = false
+
+	// A panicking fn leaves recycle unset so that the transaction is aborted.
+	recycle = true
+	return err
+}
+
+// get takes a transaction from the pool, waiting for one if all are in use,
+// and renews it.
+func (p *TxnPool) get() (*Txn, error) {
+	p.sem <- struct{}{}
+	for {
+		p.mu.Lock()
+		n := len(p.idle)
+		if n == 0 {
+			p.mu.Unlock()
+			break
+		}
+		txn := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+
+		txn.Pooled = false
+		if err := txn.Renew(); err != nil {
+			atomic.AddUint64(&p.renewErrors, 1)
+			txn.Abort()
+			continue
+		}
+		atomic.AddUint64(&p.hits, 1)
+		return txn, nil
+	}
+
+	atomic.AddUint64(&p.misses, 1)
+	txn, err := p.env.BeginTxn(nil, Readonly)
+	if err != nil {
+		<-p.sem
+		return nil, err
+	}
+	return txn, nil
+}
+
+// put resets txn and returns it to the pool.
+func (p *TxnPool) put(txn *Txn) {
+	txn.Reset()
+	txn.Pooled = true
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.discard(txn)
+		return
+	}
+	p.idle = append(p.idle, txn)
+	p.mu.Unlock()
+	<-p.sem
+}
+
+func (p *TxnPool) discard(txn *Txn) {
+	txn.managed = false
+	txn.Abort()
+	<-p.sem
+}
+
+// Stats returns the activity of the pool since it was created.
+func (p *TxnPool) Stats() TxnPoolStats {
+	p.mu.Lock()
+	idle := len(p.idle)
+	p.mu.Unlock()
+	return TxnPoolStats{
+		Hits:        atomic.LoadUint64(&p.hits),
+		Misses:      atomic.LoadUint64(&p.misses),
+		RenewErrors: atomic.LoadUint64(&p.renewErrors),
+		Idle:        idle,
+	}
+}
+
+// Close aborts the idle transactions of the pool.  Transactions in use when
+// Close is called are aborted when they are returned, as is any transaction
+// used by a later View.
+func (p *TxnPool) Close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+	for _, txn := range idle {
+		txn.Abort()
+	}
+}
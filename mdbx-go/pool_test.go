@@ -0,0 +1,147 @@
+package mdbx
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTxnPool(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put := func(v string) {
+		err := env.Update(func(txn *Txn) error {
+			return txn.Put(db, []byte("k"), []byte(v), 0)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("1")
+
+	pool, err := NewTxnPool(env, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for i := 0; i < 10; i++ {
+		err = pool.ViewCursor(db, func(cur *Cursor) error {
+			_, v, err := cur.Get([]byte("k"), nil, SetKey)
+			if err != nil {
+				return err
+			}
+			if string(v) != "1" {
+				t.Errorf("unexpected value: %q", v)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := pool.Stats()
+	if stats.Hits != 9 || stats.Misses != 1 || stats.Idle != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if r := stats.HitRate(); r != 0.9 {
+		t.Errorf("unexpected hit rate: %v", r)
+	}
+
+	// renewed transactions see later commits.
+	put("2")
+	err = pool.View(func(txn *Txn) error {
+		v, err := txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "2" {
+			t.Errorf("unexpected value: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no more than 2 transactions are begun by concurrent views.
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.View(func(txn *Txn) error {
+				_, err := txn.Get(db, []byte("k"))
+				return err
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := pool.Stats(); stats.Misses > 2 || stats.Idle > 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTxnPool_panic(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	pool, err := NewTxnPool(env, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected a panic")
+			}
+		}()
+		_ = pool.View(func(txn *Txn) error { panic("oops") })
+	}()
+
+	// the transaction was discarded and its slot released.
+	if err := pool.View(func(txn *Txn) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if stats := pool.Stats(); stats.Misses != 2 || stats.Idle != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func BenchmarkTxnPool_View(b *testing.B) {
+	env := setup(b)
+	defer clean(env, b)
+
+	pool, err := NewTxnPool(env, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+	fn := func(txn *Txn) error { return nil }
+
+	b.Run("env", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := env.View(fn); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := pool.View(fn); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
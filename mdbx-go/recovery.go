@@ -0,0 +1,65 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// OpenForRecovery opens the environment at path in Exclusive mode using the
+// meta page with the given index (see EnvInfo.Metas) instead of the latest
+// one, which shows the database as of the transaction that wrote the meta
+// page.  The environment is read-only unless writeable is true, in which
+// case writing to it discards the transactions committed after that meta
+// page.  OpenForRecovery is meant for inspecting and repairing damaged
+// databases, like the mdbx_chk utility does.
+//
+// Unlike Open, OpenForRecovery cannot pass NoTLS to mdbx, so the reader slots
+// of the environment are tied to OS threads.  View and RunTxn lock the calling
+// goroutine to its thread while a read transaction is live, a goroutine
+// calling BeginTxn must be locked to its thread for any transaction.
+//
+// See mdbx_env_open_for_recovery.
+func (env *Env) OpenForRecovery(path string, meta int, writeable bool) error {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	ret := C.mdbx_env_open_for_recovery(env._env, cpath, C.uint(meta), C.bool(writeable))
+	if ret == success {
+		env.threadBound = true
+	}
+	return operrno("mdbx_env_open_for_recovery", ret)
+}
+
+// PageCheck accounts for the pages of a database, see Txn.CheckPages.
+type PageCheck struct {
+	Used uint64 // Pages of the b-trees, including the meta pages
+	Free uint64 // Pages listed by the garbage collector
+	Lost uint64 // Pages which are neither used nor free
+}
+
+// CheckPages walks the b-trees of every database as of txn and the page lists
+// of the garbage collector, like the mdbx_chk utility does.  Every page of the
+// database must be either used or free.  An error with Errno Corrupted is
+// returned if a page is damaged, lies beyond the end of the database, is
+// accounted for twice or is lost.
+//
+// See mdbx_env_pgwalk.
+func (txn *Txn) CheckPages() (PageCheck, error) {
+	if err := txn.check("mdbx_env_pgwalk"); err != nil {
+		return PageCheck{}, err
+	}
+	var used, free, lost, bad C.uint64_t
+	ret := C.mdbxgo_check_pages(txn._txn, &used, &free, &lost, &bad)
+	check := PageCheck{Used: uint64(used), Free: uint64(free), Lost: uint64(lost)}
+	if ret != success {
+		return check, operrno("mdbx_env_pgwalk", ret)
+	}
+	if bad != 0 || lost != 0 {
+		return check, &OpError{Op: "mdbx_env_pgwalk", Errno: Corrupted}
+	}
+	return check, nil
+}
@@ -0,0 +1,150 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestTxn_CheckPages(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := make([]byte, 2500)
+	for i := 0; i < 4; i++ {
+		err = env.Update(func(txn *Txn) error {
+			var k [8]byte
+			for j := 0; j < 50; j++ {
+				binary.BigEndian.PutUint64(k[:], uint64(j))
+				if i%2 == 1 {
+					if err := txn.Del(db, k[:], nil); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := txn.Put(db, k[:], v, 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.View(func(txn *Txn) error {
+		check, err := txn.CheckPages()
+		if err != nil {
+			return err
+		}
+		if check.Free == 0 {
+			t.Errorf("no free pages after deletes: %+v", check)
+		}
+		if check.Lost != 0 || int64(check.Used+check.Free) != info.LastPNO+1 {
+			t.Errorf("pages not accounted for: %+v, last page %d", check, info.LastPNO)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnv_OpenForRecovery(t *testing.T) {
+	env := setup(t)
+	path, err := env.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 100
+	for i := 0; i < 2; i++ {
+		err = env.Update(func(txn *Txn) error {
+			var k [8]byte
+			for j := 0; j < n; j++ {
+				binary.BigEndian.PutUint64(k[:], uint64(j))
+				if err := txn.Put(db, k[:], []byte{byte(i)}, 0); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	info, err := env.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Close()
+
+	// The meta page before the last one shows the first transaction.
+	prev := -1
+	for i, m := range info.Metas {
+		if m.TxnID == info.LastTxnID-1 {
+			prev = i
+		}
+	}
+	if prev < 0 {
+		t.Fatalf("no meta page of the previous transaction: %+v", info.Metas)
+	}
+	env, err = NewEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Close()
+	if err := env.OpenForRecovery(path, prev, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read transactions are tied to their thread, the goroutines reading
+	// concurrently with others keeping the threads busy are likely to be
+	// moved to another thread if they are not locked.
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for g := 0; g < cap(errs); g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- env.View(func(txn *Txn) error {
+				var k [8]byte
+				for j := 0; j < n; j++ {
+					binary.BigEndian.PutUint64(k[:], uint64(j))
+					v, err := txn.Get(db, k[:])
+					if err != nil {
+						return err
+					}
+					if v[0] != 0 {
+						t.Errorf("key %d: value %d of the last transaction", j, v[0])
+					}
+					runtime.Gosched()
+				}
+				_, err := txn.CheckPages()
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"errors"
+)
+
+// ErrSavepointInactive is returned when a savepoint which was released, or
+// discarded by rolling back to an earlier savepoint, is used.
+var ErrSavepointInactive = errors.New("savepoint is not active")
+
+// Savepoint marks a state of a write transaction which the transaction can
+// be rolled back to without aborting it.  Savepoints are nested mdbx
+// transactions, but unlike Txn.Sub they do not hand out a separate Txn: after
+// Txn.Savepoint returns, the operations of the transaction and of its cursors
+// apply to the innermost savepoint, so the transaction keeps being used as
+// before.
+//
+//		sp, err := txn.Savepoint()
+//		...
+//		err = txn.Put(dbi, k, v, 0)
+//		if needsUndo {
+//			err = txn.RollbackTo(sp) // the Put is undone, sp is still active
+//		}
+//		...
+//		err = txn.Release(sp) // changes since sp are kept
+//
+// Committing or aborting the transaction releases or rolls back all of its
+// savepoints.
+type Savepoint struct {
+	txn   *Txn
+	outer *C.MDBX_txn     // handle of txn when the savepoint was created
+	inner *C.MDBX_txn     // nested transaction begun by the savepoint
+	curs  map[DBI]*Cursor // cursors of Txn.Cursor for outer
+}
+
+// Savepoint creates a savepoint of txn, which must be a write transaction.
+// Savepoint and the other savepoint methods fail with ErrTxnChildActive while
+// a subtransaction of txn is live.
+//
+// See mdbx_txn_begin.
+func (txn *Txn) Savepoint() (*Savepoint, error) {
+	if err := txn.check("mdbx_txn_begin"); err != nil {
+		return nil, err
+	}
+	sp := &Savepoint{txn: txn, outer: txn._txn, curs: txn.curs}
+	if err := sp.begin(); err != nil {
+		return nil, err
+	}
+	txn.curs = nil
+	txn.savepoints = append(txn.savepoints, sp)
+	return sp, nil
+}
+
+// begin starts the nested transaction of sp and makes it current in txn.
+func (sp *Savepoint) begin() error {
+	var inner *C.MDBX_txn
+	ret := C.mdbx_txn_begin(sp.txn.env._env, sp.outer, 0, &inner)
+	if ret != success {
+		return operrno("mdbx_txn_begin", ret)
+	}
+	sp.inner = inner
+	sp.txn._txn = inner
+	sp.txn.resetID()
+	return nil
+}
+
+// RollbackTo discards the changes made to txn since sp was created, including
+// those of later savepoints, which become inactive.  Sp remains active.
+// Cursors opened since sp was created must not be used afterwards.
+//
+// See mdbx_txn_abort.
+func (txn *Txn) RollbackTo(sp *Savepoint) error {
+	i, err := txn.savepointIndex(sp, "mdbx_txn_abort")
+	if err != nil {
+		return err
+	}
+	txn.closeSavepointCursors(i)
+	C.mdbx_txn_abort(sp.inner)
+	txn.savepoints = txn.savepoints[:i+1]
+	if err := sp.begin(); err != nil {
+		// sp cannot be restarted, txn continues at the state before sp.
+		txn.savepoints = txn.savepoints[:i]
+		txn._txn = sp.outer
+		txn.curs = sp.curs
+		txn.resetID()
+		return err
+	}
+	return nil
+}
+
+// Release keeps the changes made to txn since sp was created and makes sp
+// and later savepoints inactive.  The changes can still be rolled back with
+// an earlier savepoint or by aborting txn.
+//
+// See mdbx_txn_commit.
+func (txn *Txn) Release(sp *Savepoint) error {
+	i, err := txn.savepointIndex(sp, "mdbx_txn_commit")
+	if err != nil {
+		return err
+	}
+	return txn.release(i)
+}
+
+// release commits the nested transactions of the savepoints of txn from the
+// innermost one to the i-th one.
+func (txn *Txn) release(i int) error {
+	txn.closeSavepointCursors(i)
+	for j := len(txn.savepoints) - 1; j >= i; j-- {
+		sp := txn.savepoints[j]
+		ret := C.mdbx_txn_commit_ex(sp.inner, nil)
+
+		// Whether it succeeded or not the nested transaction is over.
+		txn.savepoints = txn.savepoints[:j]
+		txn._txn = sp.outer
+		txn.curs = sp.curs
+		txn.resetID()
+		if ret != success {
+			return operrno("mdbx_txn_commit_ex", ret)
+		}
+	}
+	return nil
+}
+
+// unwindSavepoints makes the handle of txn the one it had before its first
+// savepoint, whose termination ends the nested transactions as well.
+func (txn *Txn) unwindSavepoints() {
+	if len(txn.savepoints) > 0 {
+		txn._txn = txn.savepoints[0].outer
+		txn.savepoints = nil
+	}
+}
+
+func (txn *Txn) savepointIndex(sp *Savepoint, op string) (int, error) {
+	if err := txn.check(op); err != nil {
+		return -1, err
+	}
+	for i, x := range txn.savepoints {
+		if x == sp {
+			return i, nil
+		}
+	}
+	return -1, &OpError{Op: op, Errno: ErrSavepointInactive}
+}
+
+// closeSavepointCursors closes the cursors of Txn.Cursor opened since the
+// i-th savepoint of txn was created.
+func (txn *Txn) closeSavepointCursors(i int) {
+	closeCursorMap(txn.curs)
+	for _, sp := range txn.savepoints[i+1:] {
+		closeCursorMap(sp.curs)
+	}
+}
@@ -0,0 +1,247 @@
+package mdbx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTxn_Savepoint(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var db DBI
+	err := env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBI("testdb", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		has := func(k string) bool {
+			_, err := txn.Get(db, []byte(k))
+			if err != nil && !IsNotFound(err) {
+				t.Fatal(err)
+			}
+			return err == nil
+		}
+		put := func(k string) {
+			if err := txn.Put(db, []byte(k), []byte(k), 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		put("a")
+		sp1, err := txn.Savepoint()
+		if err != nil {
+			return err
+		}
+		put("b")
+		sp2, err := txn.Savepoint()
+		if err != nil {
+			return err
+		}
+		put("c")
+		cur, err := txn.Cursor(db)
+		if err != nil {
+			return err
+		}
+		if _, _, err := cur.Get(nil, nil, Last); err != nil {
+			return err
+		}
+
+		// rolling back to sp1 undoes b and c and discards sp2.
+		if err := txn.RollbackTo(sp1); err != nil {
+			return err
+		}
+		if !has("a") || has("b") || has("c") {
+			t.Errorf("unexpected state after rollback")
+		}
+		if err := txn.Release(sp2); !errors.Is(err, ErrSavepointInactive) {
+			t.Errorf("release of a discarded savepoint: unexpected error: %v", err)
+		}
+
+		// sp1 is still active.
+		put("d")
+		if err := txn.RollbackTo(sp1); err != nil {
+			return err
+		}
+		if has("d") {
+			t.Errorf("unexpected state after second rollback")
+		}
+		put("e")
+		if err := txn.Release(sp1); err != nil {
+			return err
+		}
+		if err := txn.RollbackTo(sp1); !errors.Is(err, ErrSavepointInactive) {
+			t.Errorf("rollback to a released savepoint: unexpected error: %v", err)
+		}
+
+		// savepoints left active are committed with txn.
+		if _, err := txn.Savepoint(); err != nil {
+			return err
+		}
+		put("f")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		for k, want := range map[string]bool{"a": true, "b": false, "d": false, "e": true, "f": true} {
+			_, err := txn.Get(db, []byte(k))
+			if err != nil && !IsNotFound(err) {
+				return err
+			}
+			if (err == nil) != want {
+				t.Errorf("%s: unexpected presence after commit", k)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// aborting txn rolls its savepoints back.
+	txn, err := env.BeginTxn(nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Savepoint(); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(db, []byte("g"), nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	txn.Abort()
+	err = env.View(func(txn *Txn) error {
+		_, err := txn.Get(db, []byte("g"))
+		if !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_childActive(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenDBI("testdb", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		err = txn.Sub(func(sub *Txn) error {
+			if err := txn.Put(db, []byte("k"), nil, 0); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("parent put: unexpected error: %v", err)
+			}
+			if _, err := txn.Get(db, []byte("k")); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("parent get: unexpected error: %v", err)
+			}
+			if _, _, err := cur.Get(nil, nil, First); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("parent cursor: unexpected error: %v", err)
+			}
+			if _, err := txn.Savepoint(); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("parent savepoint: unexpected error: %v", err)
+			}
+			if err := txn.Sub(func(*Txn) error { return nil }); !errors.Is(err, ErrTxnChildActive) {
+				t.Errorf("parent sub: unexpected error: %v", err)
+			}
+			return sub.Put(db, []byte("k"), []byte("v"), 0)
+		})
+		if err != nil {
+			return err
+		}
+
+		// the parent is usable again.
+		v, err := txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		if string(v) != "v" {
+			t.Errorf("unexpected value: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxn_childActive_methods(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	methods := []struct {
+		name string
+		call func(txn *Txn, db DBI) error
+	}{
+		{"OpenDBI", func(txn *Txn, db DBI) error {
+			_, err := txn.OpenDBI("testdb", 0, nil, nil)
+			return err
+		}},
+		{"OpenDBISimple", func(txn *Txn, db DBI) error {
+			_, err := txn.OpenDBISimple("testdb", 0)
+			return err
+		}},
+		{"OpenRoot", func(txn *Txn, db DBI) error {
+			_, err := txn.OpenRoot(0)
+			return err
+		}},
+		{"Flags", func(txn *Txn, db DBI) error {
+			_, err := txn.Flags(db)
+			return err
+		}},
+		{"FlagsEx", func(txn *Txn, db DBI) error {
+			_, _, err := txn.FlagsEx(db)
+			return err
+		}},
+		{"Info", func(txn *Txn, db DBI) error {
+			_, err := txn.Info(false)
+			return err
+		}},
+		{"StatDBI", func(txn *Txn, db DBI) error {
+			_, err := txn.StatDBI(db)
+			return err
+		}},
+		{"Drop", func(txn *Txn, db DBI) error {
+			return txn.Drop(db, false)
+		}},
+		{"Sequence", func(txn *Txn, db DBI) error {
+			_, err := txn.Sequence(db, 1)
+			return err
+		}},
+	}
+	for _, m := range methods {
+		t.Run(m.name, func(t *testing.T) {
+			err := env.Update(func(txn *Txn) error {
+				db, err := txn.OpenDBI("testdb", Create, nil, nil)
+				if err != nil {
+					return err
+				}
+				err = txn.Sub(func(sub *Txn) error {
+					if err := m.call(txn, db); !errors.Is(err, ErrTxnChildActive) {
+						t.Errorf("parent: unexpected error: %v", err)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				return m.call(txn, db)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
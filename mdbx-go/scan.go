@@ -0,0 +1,203 @@
+package mdbx
+
+import (
+	"bytes"
+)
+
+// Scanner iterates over the items of a database using a Cursor, replacing
+// the usual loop over Cursor.Get with its IsNotFound check.
+//
+//		s := mdbx.NewScanner(cur).Prefix([]byte("user/"))
+//		for s.Scan() {
+//			fmt.Printf("%s=%s\n", s.Key(), s.Val())
+//		}
+//		if err := s.Err(); err != nil {
+//			return err
+//		}
+//
+// By default a Scanner visits every key of the database once, in the order
+// of the database, along with its first duplicate (or its last duplicate when
+// scanning in reverse).  Range, Prefix, Reverse and Dups change this and must
+// be called before the first call to Scan.
+//
+// The slices returned by Key and Val follow the rules of Cursor.Get.  If the
+// transaction has RawRead set they reference the memory map, like those of
+// Txn.Get.  They stay valid across calls to Scan until the transaction
+// terminates, except that in a write transaction an item may change when the
+// transaction writes to the page holding it (see Txn.IsDirty).  A Scanner does
+// not own its cursor, which must be closed by the caller.
+type Scanner struct {
+	cur *Cursor
+
+	from    []byte
+	to      []byte
+	prefix  []byte
+	reverse bool
+	dups    bool
+
+	started bool
+	done    bool
+	key     []byte
+	val     []byte
+	err     error
+}
+
+// NewScanner returns a Scanner over the database of cur.  The position of
+// cur is changed by the Scanner.
+func NewScanner(cur *Cursor) *Scanner {
+	return &Scanner{cur: cur}
+}
+
+// Range restricts s to keys k such that from <= k < to, according to the
+// comparison function of the database.  A nil from or to leaves the range
+// unbounded on that side.
+func (s *Scanner) Range(from, to []byte) *Scanner {
+	s.from = from
+	s.to = to
+	return s
+}
+
+// Prefix restricts s to keys beginning with prefix.  Prefix is only
+// meaningful for databases using the default, lexicographic, key order.
+func (s *Scanner) Prefix(prefix []byte) *Scanner {
+	s.prefix = prefix
+	return s
+}
+
+// Reverse makes s visit keys in descending order.
+func (s *Scanner) Reverse() *Scanner {
+	s.reverse = true
+	return s
+}
+
+// Dups makes s visit every duplicate of each key in a DupSort database
+// instead of only one item per key.
+func (s *Scanner) Dups() *Scanner {
+	s.dups = true
+	return s
+}
+
+// Scan advances s to the next item, which is then available through Key and
+// Val.  Scan returns false when there are no more items or an error was
+// encountered, in which case Err returns the error.
+func (s *Scanner) Scan() bool {
+	if s.done {
+		return false
+	}
+	var err error
+	if !s.started {
+		s.started = true
+		s.key, s.val, err = s.seek()
+	} else {
+		s.key, s.val, err = s.cur.Get(nil, nil, s.step())
+	}
+	if err != nil {
+		if !IsNotFound(err) {
+			s.err = err
+		}
+		return s.stop()
+	}
+	if !s.inRange(s.key) {
+		return s.stop()
+	}
+	return true
+}
+
+func (s *Scanner) stop() bool {
+	s.done = true
+	s.key = nil
+	s.val = nil
+	return false
+}
+
+// Key returns the key of the current item.
+func (s *Scanner) Key() []byte {
+	return s.key
+}
+
+// Val returns the value of the current item.
+func (s *Scanner) Val() []byte {
+	return s.val
+}
+
+// Err returns the first error encountered by s, if any.  Reaching the end of
+// the database or of the scanned range is not an error.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+func (s *Scanner) step() uint {
+	switch {
+	case s.reverse && s.dups:
+		return Prev
+	case s.reverse:
+		return PrevNoDup
+	case s.dups:
+		return Next
+	}
+	return NextNoDup
+}
+
+// seek positions the cursor on the first item to visit.
+func (s *Scanner) seek() (key, val []byte, err error) {
+	if !s.reverse {
+		start := s.from
+		if s.prefix != nil && (start == nil || s.cmp(s.prefix, start) > 0) {
+			start = s.prefix
+		}
+		if start == nil {
+			return s.cur.Get(nil, nil, First)
+		}
+		return s.cur.Get(start, nil, SetRange)
+	}
+
+	// Position on the first key past the range and step back from it.
+	end := s.to
+	if s.prefix != nil {
+		if next := prefixEnd(s.prefix); next != nil && (end == nil || s.cmp(next, end) < 0) {
+			end = next
+		}
+	}
+	if end == nil {
+		return s.cur.Get(nil, nil, Last)
+	}
+	_, _, err = s.cur.Get(end, nil, SetRange)
+	if IsNotFound(err) {
+		return s.cur.Get(nil, nil, Last)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.cur.Get(nil, nil, s.step())
+}
+
+func (s *Scanner) inRange(key []byte) bool {
+	if s.prefix != nil && !bytes.HasPrefix(key, s.prefix) {
+		return false
+	}
+	if s.from != nil && s.cmp(key, s.from) < 0 {
+		return false
+	}
+	if s.to != nil && s.cmp(key, s.to) >= 0 {
+		return false
+	}
+	return true
+}
+
+func (s *Scanner) cmp(a, b []byte) int {
+	return s.cur.txn.Cmp(s.cur.DBI(), a, b)
+}
+
+// prefixEnd returns the smallest key greater than every key beginning with
+// prefix, or nil if there is no such key.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
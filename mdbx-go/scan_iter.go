@@ -0,0 +1,53 @@
+//go:build go1.23
+// +build go1.23
+
+package mdbx
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the remaining items of s, for use with
+// range-over-func.  Breaking out of the loop leaves s positioned on the last
+// item visited.  Err must be checked after the loop.
+//
+//		s := mdbx.NewScanner(cur).Range(from, to)
+//		for k, v := range s.All() {
+//			...
+//		}
+//		if err := s.Err(); err != nil {
+//			return err
+//		}
+func (s *Scanner) All() iter.Seq2[[]byte, []byte] {
+	return func(yield func([]byte, []byte) bool) {
+		for s.Scan() {
+			if !yield(s.key, s.val) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over the keys of the remaining items of s.  See
+// All.
+func (s *Scanner) Keys() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		for s.Scan() {
+			if !yield(s.key) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the remaining decoded items of s.  See
+// Scanner.All.
+func (s *TableScanner[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for s.Scan() {
+			if !yield(s.key, s.val) {
+				return
+			}
+		}
+	}
+}
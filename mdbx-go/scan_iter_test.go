@@ -0,0 +1,64 @@
+//go:build go1.23
+// +build go1.23
+
+package mdbx
+
+import (
+	"testing"
+)
+
+func TestScanner_All(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "scan", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		for i := 0; i < 10; i++ {
+			if err := txn.Put(db, []byte{byte(i)}, []byte{byte(i)}, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		s := NewScanner(cur).Range([]byte{2}, nil)
+		var n byte
+		for k, v := range s.All() {
+			if k[0] != n+2 || v[0] != n+2 {
+				t.Errorf("unexpected item %d: %x=%x", n, k, v)
+			}
+			n++
+			if n == 5 {
+				break
+			}
+		}
+		if n != 5 {
+			t.Errorf("unexpected number of items: %d", n)
+		}
+		// the scan continues where the loop stopped.
+		n = 0
+		for range s.Keys() {
+			n++
+		}
+		if n != 3 {
+			t.Errorf("unexpected number of remaining keys: %d (!= 3)", n)
+		}
+		return s.Err()
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
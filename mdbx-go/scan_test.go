@@ -0,0 +1,117 @@
+package mdbx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "scan", Create|DupSort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := [][2]string{
+		{"a", "1"},
+		{"b/1", "1"},
+		{"b/1", "2"},
+		{"b/2", "1"},
+		{"b\xff", "1"},
+		{"c", "1"},
+		{"c", "2"},
+	}
+	err = env.Update(func(txn *Txn) error {
+		for _, item := range items {
+			if err := txn.Put(db, []byte(item[0]), []byte(item[1]), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name string
+		opt  func(s *Scanner) *Scanner
+		want string
+	}{
+		{"all", func(s *Scanner) *Scanner { return s }, "a=1 b/1=1 b/2=1 b\xff=1 c=1"},
+		{"dups", (*Scanner).Dups, "a=1 b/1=1 b/1=2 b/2=1 b\xff=1 c=1 c=2"},
+		{"reverse", (*Scanner).Reverse, "c=2 b\xff=1 b/2=1 b/1=2 a=1"},
+		{"reverse dups", func(s *Scanner) *Scanner { return s.Reverse().Dups() }, "c=2 c=1 b\xff=1 b/2=1 b/1=2 b/1=1 a=1"},
+		{"range", func(s *Scanner) *Scanner { return s.Range([]byte("b"), []byte("c")) }, "b/1=1 b/2=1 b\xff=1"},
+		{"range open", func(s *Scanner) *Scanner { return s.Range([]byte("b/2"), nil) }, "b/2=1 b\xff=1 c=1"},
+		{"range reverse", func(s *Scanner) *Scanner { return s.Range([]byte("b/1"), []byte("b\xff")).Reverse() }, "b/2=1 b/1=2"},
+		{"range empty", func(s *Scanner) *Scanner { return s.Range([]byte("d"), nil) }, ""},
+		{"prefix", func(s *Scanner) *Scanner { return s.Prefix([]byte("b/")).Dups() }, "b/1=1 b/1=2 b/2=1"},
+		{"prefix reverse", func(s *Scanner) *Scanner { return s.Prefix([]byte("b/")).Reverse().Dups() }, "b/2=1 b/1=2 b/1=1"},
+		{"prefix ff reverse", func(s *Scanner) *Scanner { return s.Prefix([]byte("b\xff")).Reverse() }, "b\xff=1"},
+		{"prefix range", func(s *Scanner) *Scanner { return s.Prefix([]byte("b")).Range([]byte("b/2"), nil) }, "b/2=1 b\xff=1"},
+	} {
+		err = env.View(func(txn *Txn) error {
+			cur, err := txn.OpenCursor(db)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+
+			var got []string
+			s := test.opt(NewScanner(cur))
+			for s.Scan() {
+				got = append(got, string(s.Key())+"="+string(s.Val()))
+			}
+			if s.Err() != nil {
+				return s.Err()
+			}
+			if s.Scan() {
+				t.Errorf("%s: Scan returned true after the end", test.name)
+			}
+			if g := strings.Join(got, " "); g != test.want {
+				t.Errorf("%s: unexpected items: %q (!= %q)", test.name, g, test.want)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestScanner_err(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "scan", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.View(func(txn *Txn) error {
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		cur.Close()
+		// restore the txn of the closed cursor so Get fails in mdbx.
+		cur.txn = txn
+
+		s := NewScanner(cur)
+		if s.Scan() {
+			t.Errorf("scan of an invalid cursor succeeded")
+		}
+		if s.Err() == nil {
+			t.Errorf("expected error")
+		}
+		if !reflect.DeepEqual(s.Key(), []byte(nil)) {
+			t.Errorf("unexpected key: %q", s.Key())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
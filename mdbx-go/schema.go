@@ -0,0 +1,323 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DefaultSchemaMeta is the name of the table in which a Schema records its
+// version and applied migrations unless Schema.Meta is set.
+const DefaultSchemaMeta = "_schema"
+
+// persistentFlags are the database flags which are stored in the database and
+// must be the same every time it is opened.
+const persistentFlags = ReverseKey | DupSort | DupFixed | ReverseDup |
+	IntegerKey | IntegerDup
+
+// schemaVersionKey is the key of the schema version in the meta table.
+var schemaVersionKey = []byte("version")
+
+// TableSpec declares a named database of a Schema.
+type TableSpec struct {
+	Name  string
+	Flags uint    // Persistent flags such as DupSort or DupFixed
+	Cmp   CmpFunc // Custom key comparison function, see Txn.OpenDBI
+	DCmp  CmpFunc // Custom duplicate comparison function, see Txn.OpenDBI
+}
+
+// Migration changes the layout of the database from the previous version to
+// Version.  Run is called in the write transaction which records the
+// migration, before the tables of the schema are opened.
+type Migration struct {
+	Version uint64
+	Name    string
+	Run     TxnOp
+}
+
+// SchemaFlagsError is returned by Schema.Open when an existing table was
+// created with flags other than those it is declared with.
+type SchemaFlagsError struct {
+	Table    string
+	Declared uint
+	Actual   uint
+}
+
+func (err *SchemaFlagsError) Error() string {
+	return fmt.Sprintf("table %q: flags %#x do not match declared flags %#x", err.Table, err.Actual, err.Declared)
+}
+
+// SchemaCmpError is returned by Schema.Open when a table is declared with
+// comparison functions other than those it was created with.  Comparison
+// functions are identified by their name (see CmpName).
+type SchemaCmpError struct {
+	Table    string
+	Declared string
+	Recorded string
+}
+
+func (err *SchemaCmpError) Error() string {
+	return fmt.Sprintf("table %q: comparators %q do not match recorded comparators %q", err.Table, err.Declared, err.Recorded)
+}
+
+// Schema declares the named databases of an environment and the migrations
+// bringing an existing environment to its current layout.  The environment
+// must allow enough named databases for the tables, the migrations and the
+// meta table (see Env.SetMaxDBs).
+//
+//		schema := &mdbx.Schema{
+//			Tables: []mdbx.TableSpec{
+//				{Name: "blocks"},
+//				{Name: "index", Flags: mdbx.DupSort | mdbx.DupFixed},
+//			},
+//			Migrations: []mdbx.Migration{
+//				{Version: 1, Name: "rename cst", Run: mdbx.RenameTable("PLAIN-CST2", "index")},
+//			},
+//		}
+//		err := schema.Open(env)
+//		...
+//		dbi := schema.DBI("index")
+type Schema struct {
+	Tables     []TableSpec
+	Migrations []Migration
+
+	// Meta is the name of the table recording the schema version.  If empty
+	// DefaultSchemaMeta is used.
+	Meta string
+
+	dbis    map[string]DBI
+	version uint64
+}
+
+// Open applies the migrations of s which are newer than the recorded version
+// of the environment, in order of version, then opens every declared table,
+// creating the missing ones, and checks that existing tables have the
+// declared flags and comparison functions.  All of this happens in a single
+// write transaction, which is committed only if every step succeeds.
+//
+// Mdbx does not store comparison functions, so Open records the names of
+// those of each table in the meta table and checks them when the table is
+// opened again.  The default comparison functions are recorded according to
+// the flags of the table, as "integer" with IntegerKey or IntegerDup, as
+// "reverse" with ReverseKey or ReverseDup and as "lexical" otherwise.  Tables
+// using a comparison function without a name (see CmpName) are not checked.
+//
+// Open locks the calling goroutine to its thread, like Env.Update.
+func (s *Schema) Open(env *Env) error {
+	for i := 1; i < len(s.Migrations); i++ {
+		if s.Migrations[i].Version <= s.Migrations[i-1].Version {
+			return fmt.Errorf("migration %q: versions are not increasing", s.Migrations[i].Name)
+		}
+	}
+	meta := s.Meta
+	if meta == "" {
+		meta = DefaultSchemaMeta
+	}
+
+	dbis := make(map[string]DBI, len(s.Tables))
+	var version uint64
+	err := env.Update(func(txn *Txn) error {
+		mdbi, err := txn.OpenDBI(meta, Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		version, err = s.migrate(txn, mdbi)
+		if err != nil {
+			return err
+		}
+		for _, spec := range s.Tables {
+			dbi, err := openTableSpec(txn, mdbi, spec)
+			if err != nil {
+				return err
+			}
+			dbis[spec.Name] = dbi
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.dbis = dbis
+	s.version = version
+	return nil
+}
+
+// migrate runs the pending migrations and returns the resulting version.
+func (s *Schema) migrate(txn *Txn, meta DBI) (uint64, error) {
+	var version uint64
+	v, err := txn.Get(meta, schemaVersionKey)
+	switch {
+	case IsNotFound(err):
+	case err != nil:
+		return 0, err
+	case len(v) != 8:
+		return 0, fmt.Errorf("invalid schema version %x", v)
+	default:
+		version = binary.BigEndian.Uint64(v)
+	}
+
+	for _, m := range s.Migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := m.Run(txn); err != nil {
+			return 0, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		version = m.Version
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], version)
+		key := append([]byte("migration/"), k[:]...)
+		if err := txn.Put(meta, key, []byte(m.Name), 0); err != nil {
+			return 0, err
+		}
+		if err := txn.Put(meta, schemaVersionKey, k[:], 0); err != nil {
+			return 0, err
+		}
+	}
+	return version, nil
+}
+
+// openTableSpec opens the table declared by spec, creating it if needed.
+func openTableSpec(txn *Txn, meta DBI, spec TableSpec) (DBI, error) {
+	var cmps string
+	kname := tableCmpName(spec.Cmp, spec.Flags, IntegerKey, ReverseKey)
+	dname := tableCmpName(spec.DCmp, spec.Flags, IntegerDup, ReverseDup)
+	if kname != "" && dname != "" {
+		cmps = kname + "," + dname
+	}
+	cmpKey := []byte("cmp/" + spec.Name)
+
+	// DBAccede opens an existing table whatever its flags, so that a mismatch
+	// can be reported in a meaningful way.
+	dbi, err := txn.OpenDBI(spec.Name, DBAccede, spec.Cmp, spec.DCmp)
+	if IsNotFound(err) {
+		dbi, err = txn.OpenDBI(spec.Name, Create|spec.Flags, spec.Cmp, spec.DCmp)
+		if err == nil && cmps != "" {
+			err = txn.Put(meta, cmpKey, []byte(cmps), 0)
+		}
+		return dbi, err
+	}
+	if err != nil {
+		return 0, err
+	}
+	flags, _, err := txn.FlagsEx(dbi)
+	if err != nil {
+		return 0, err
+	}
+	if flags&persistentFlags != spec.Flags&persistentFlags {
+		return 0, &SchemaFlagsError{Table: spec.Name, Declared: spec.Flags, Actual: flags}
+	}
+	if cmps != "" {
+		recorded, err := txn.Get(meta, cmpKey)
+		switch {
+		case IsNotFound(err):
+			err = txn.Put(meta, cmpKey, []byte(cmps), 0)
+		case err == nil && string(recorded) != cmps:
+			err = &SchemaCmpError{Table: spec.Name, Declared: cmps, Recorded: string(recorded)}
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return dbi, nil
+}
+
+// tableCmpName returns the name recorded for the comparison function f of a
+// table with flags.  A nil f stands for the default comparison function,
+// which the integer and reverse flags select.
+func tableCmpName(f CmpFunc, flags, integer, reverse uint) string {
+	if f == nil {
+		switch {
+		case flags&integer != 0:
+			return "integer"
+		case flags&reverse != 0:
+			return "reverse"
+		}
+	}
+	return CmpName(f)
+}
+
+// DBI returns the handle of a declared table.  DBI panics if name was not
+// declared or s is not open.
+func (s *Schema) DBI(name string) DBI {
+	dbi, ok := s.dbis[name]
+	if !ok {
+		panic(fmt.Sprintf("table %q is not declared in the open schema", name))
+	}
+	return dbi
+}
+
+// Version returns the version of the environment recorded when s was opened.
+func (s *Schema) Version() uint64 {
+	return s.version
+}
+
+// CopyTable returns a migration step copying every item of table from into
+// table to, which is created with the same flags if it does not exist.  The
+// migration helpers do not support tables with custom comparison functions.
+func CopyTable(from, to string) TxnOp {
+	return func(txn *Txn) error {
+		_, err := copyTable(txn, from, to)
+		return err
+	}
+}
+
+// RenameTable returns a migration step renaming table from to to.  Because
+// mdbx cannot rename a table the items are copied and the old table dropped.
+func RenameTable(from, to string) TxnOp {
+	return func(txn *Txn) error {
+		src, err := copyTable(txn, from, to)
+		if err != nil {
+			return err
+		}
+		return txn.Drop(src, true)
+	}
+}
+
+// DropTable returns a migration step deleting table name.  A missing table is
+// not an error.
+func DropTable(name string) TxnOp {
+	return func(txn *Txn) error {
+		dbi, err := txn.OpenDBI(name, DBAccede, nil, nil)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return txn.Drop(dbi, true)
+	}
+}
+
+func copyTable(txn *Txn, from, to string) (DBI, error) {
+	src, err := txn.OpenDBI(from, DBAccede, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	flags, err := txn.Flags(src)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := txn.OpenDBI(to, Create|flags&persistentFlags, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cur, err := txn.OpenCursor(src)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close()
+	dcur, err := txn.OpenCursor(dst)
+	if err != nil {
+		return 0, err
+	}
+	defer dcur.Close()
+
+	s := NewScanner(cur).Dups()
+	for s.Scan() {
+		if err := dcur.Put(s.Key(), s.Val(), 0); err != nil {
+			return 0, err
+		}
+	}
+	return src, s.Err()
+}
@@ -0,0 +1,171 @@
+package mdbx
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	// an existing layout with a table to migrate.
+	err := env.Update(func(txn *Txn) error {
+		dbi, err := txn.OpenDBI("PLAIN-CST2", Create|DupSort, nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, v := range []string{"1", "2"} {
+			if err := txn.Put(dbi, []byte("k"), []byte(v), 0); err != nil {
+				return err
+			}
+		}
+		_, err = txn.OpenDBI("obsolete", Create, nil, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var runs int
+	schema := &Schema{
+		Tables: []TableSpec{
+			{Name: "plain", Flags: DupSort},
+			{Name: "blocks"},
+		},
+		Migrations: []Migration{
+			{Version: 1, Name: "rename", Run: RenameTable("PLAIN-CST2", "plain")},
+			{Version: 2, Name: "drop", Run: DropTable("obsolete")},
+			{Version: 5, Name: "count", Run: func(txn *Txn) error { runs++; return nil }},
+		},
+	}
+	if err := schema.Open(env); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Version() != 5 || runs != 1 {
+		t.Errorf("unexpected version %d after %d runs", schema.Version(), runs)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(schema.DBI("plain"))
+		if err != nil {
+			return err
+		}
+		if stat.Entries != 2 {
+			t.Errorf("unexpected entries: %d (!= 2)", stat.Entries)
+		}
+		for _, name := range []string{"PLAIN-CST2", "obsolete"} {
+			if _, err := txn.OpenDBISimple(name, 0); !IsNotFound(err) {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	// migrations are not run twice.
+	if err := schema.Open(env); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 1 {
+		t.Errorf("migration was run again")
+	}
+
+	bad := &Schema{Tables: []TableSpec{{Name: "plain", Flags: DupSort | DupFixed}}}
+	err = bad.Open(env)
+	var ferr *SchemaFlagsError
+	if !errors.As(err, &ferr) || ferr.Table != "plain" || ferr.Actual&DupFixed != 0 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSchema_migrationError(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	errFail := errors.New("fail")
+	schema := &Schema{
+		Tables: []TableSpec{{Name: "t"}},
+		Migrations: []Migration{
+			{Version: 1, Name: "ok", Run: func(txn *Txn) error { return nil }},
+			{Version: 2, Name: "fail", Run: func(txn *Txn) error { return errFail }},
+		},
+	}
+	if err := schema.Open(env); !errors.Is(err, errFail) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// nothing was committed.
+	schema.Migrations = schema.Migrations[:1]
+	if err := schema.Open(env); err != nil {
+		t.Fatal(err)
+	}
+	if schema.Version() != 1 {
+		t.Errorf("unexpected version: %d", schema.Version())
+	}
+}
+
+func TestSchema_cmpFlags(t *testing.T) {
+	env := setup(t)
+	path, err := env.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	defer func() { env.Close() }()
+
+	schema := &Schema{Tables: []TableSpec{
+		{Name: "ints", Flags: IntegerKey | DupSort | DupFixed | IntegerDup},
+		{Name: "reversed", Flags: ReverseKey},
+		{Name: "plain"},
+	}}
+	if err := schema.Open(env); err != nil {
+		t.Fatal(err)
+	}
+	err = env.View(func(txn *Txn) error {
+		meta, err := txn.OpenDBISimple(DefaultSchemaMeta, 0)
+		if err != nil {
+			return err
+		}
+		for name, want := range map[string]string{
+			"ints":     "integer,integer",
+			"reversed": "reverse,lexical",
+			"plain":    "lexical,lexical",
+		} {
+			v, err := txn.Get(meta, []byte("cmp/"+name))
+			if err != nil {
+				return err
+			}
+			if string(v) != want {
+				t.Errorf("%s: recorded comparators %q (!= %q)", name, v, want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a comparison function replacing the integer order is reported.  The
+	// environment is opened again, mdbx does not let an open table change its
+	// comparison function.
+	env.Close()
+	env, err = NewEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.SetMaxDBs(16); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Open(path, 0, 0644); err != nil {
+		t.Fatal(err)
+	}
+	bad := &Schema{Tables: []TableSpec{{Name: "ints", Flags: IntegerKey | DupSort | DupFixed | IntegerDup, Cmp: CmpReverseLexical()}}}
+	err = bad.Open(env)
+	var cerr *SchemaCmpError
+	if !errors.As(err, &cerr) || cerr.Recorded != "integer,integer" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
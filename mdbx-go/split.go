@@ -0,0 +1,166 @@
+package mdbx
+
+import (
+	"runtime"
+)
+
+// SplitOp performs one step of a write workload run by a Splitter.  Pos is
+// the position at which the step starts and next the position of the
+// following step, or nil once the workload is complete.  Positions are opaque
+// to the Splitter, typically they are the next key to process.
+//
+// A step may be run again from the same position after its transaction was
+// aborted, so it must only depend on pos and the database.
+type SplitOp func(txn *Txn, pos []byte) (next []byte, err error)
+
+// Splitter runs a write workload which is too large for a single transaction
+// as a sequence of transactions.  After each step the space used by the
+// current transaction is checked with Txn.Info and the transaction is
+// committed once its dirty space reaches MaxDirty or the space left before
+// TxnFull falls below MinLeftover.  A transaction which fails with TxnFull
+// anyway is aborted and the workload resumes from the last commit with half
+// the dirty space limit.
+//
+// If ProgressKey is set the position of the next step is stored under it in
+// ProgressDBI with every commit, and removed by the last one, so that Run
+// resumes an interrupted workload from its last commit.
+type Splitter struct {
+	// MaxDirty is the dirty space (see TxInfo.SpaceDirty) at which a
+	// transaction is committed.
+	MaxDirty uint64
+
+	// MinLeftover is the space left before TxnFull (see
+	// TxInfo.SpaceLeftover) below which a transaction is committed.
+	MinLeftover uint64
+
+	// ProgressDBI and ProgressKey locate the stored position of an
+	// interrupted workload.  The position is not stored if ProgressKey is
+	// nil.
+	ProgressDBI DBI
+	ProgressKey []byte
+
+	// OnCommit, if not nil, is called after each commit with the position of
+	// the next step, or nil after the last commit.
+	OnCommit func(pos []byte)
+
+	env *Env
+}
+
+// NewSplitter returns a Splitter for env.  MaxDirty defaults to half the
+// dirty page limit of the environment (see OptTxnDpLimit), so transactions
+// are committed before their dirty pages have to be spilled.
+func NewSplitter(env *Env) (*Splitter, error) {
+	limit, err := env.GetOption(OptTxnDpLimit)
+	if err != nil {
+		return nil, err
+	}
+	info, err := env.Info()
+	if err != nil {
+		return nil, err
+	}
+	return &Splitter{
+		MaxDirty: limit * uint64(info.PageSize) / 2,
+		env:      env,
+	}, nil
+}
+
+// Run runs fn from position start, or from the stored position if there is
+// one, until fn returns a nil position.  A nil start is passed to fn as an
+// empty position.
+//
+// Run locks the calling goroutine to its thread while it runs, like
+// Env.Update.
+func (s *Splitter) Run(start []byte, fn SplitOp) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	pos := start
+	if pos == nil {
+		pos = []byte{}
+	}
+	if s.ProgressKey != nil {
+		err := s.env.View(func(txn *Txn) error {
+			v, err := txn.Get(s.ProgressDBI, s.ProgressKey)
+			if err == nil {
+				pos = v
+			}
+			if IsNotFound(err) {
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	maxDirty := s.MaxDirty
+	for {
+		var steps int
+		var next []byte
+		err := s.env.UpdateLocked(func(txn *Txn) (err error) {
+			steps = 0
+			next = pos
+			for next != nil {
+				steps++
+				next, err = fn(txn, next)
+				if err != nil {
+					return err
+				}
+				if next == nil {
+					break
+				}
+				full, err := spaceExhausted(txn, maxDirty, s.MinLeftover)
+				if err != nil {
+					return err
+				}
+				if full {
+					// next may reference memory of txn.
+					next = append([]byte{}, next...)
+					break
+				}
+			}
+			return s.saveProgress(txn, next)
+		})
+		if IsErrno(err, TxnFull) && steps > 1 {
+			// Fewer steps per transaction may fit.
+			maxDirty /= 2
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pos = next
+		if s.OnCommit != nil {
+			s.OnCommit(pos)
+		}
+		if pos == nil {
+			return nil
+		}
+	}
+}
+
+func (s *Splitter) saveProgress(txn *Txn, pos []byte) error {
+	if s.ProgressKey == nil {
+		return nil
+	}
+	if pos == nil {
+		err := txn.Del(s.ProgressDBI, s.ProgressKey, nil)
+		if IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return txn.Put(s.ProgressDBI, s.ProgressKey, pos, 0)
+}
+
+// spaceExhausted reports whether the write transaction txn should be
+// committed because its dirty space reached maxDirty or the space left before
+// TxnFull is below minLeftover.
+func spaceExhausted(txn *Txn, maxDirty, minLeftover uint64) (bool, error) {
+	info, err := txn.Info(false)
+	if err != nil {
+		return false, err
+	}
+	return info.SpaceDirty >= maxDirty || info.SpaceLeftover < minLeftover, nil
+}
@@ -0,0 +1,149 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// splitStep returns a SplitOp storing one item per step until n items are
+// stored.  Positions are big-endian item numbers.
+func splitStep(db DBI, n uint64) SplitOp {
+	return func(txn *Txn, pos []byte) ([]byte, error) {
+		var i uint64
+		if len(pos) > 0 {
+			i = binary.BigEndian.Uint64(pos)
+		}
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], i)
+		if err := txn.Put(db, k[:], make([]byte, 64), 0); err != nil {
+			return nil, err
+		}
+		if i+1 == n {
+			return nil, nil
+		}
+		binary.BigEndian.PutUint64(k[:], i+1)
+		return k[:], nil
+	}
+}
+
+func TestSplitter(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "split", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := openDBI(env, "meta", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewSplitter(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MaxDirty == 0 {
+		t.Errorf("no default dirty limit")
+	}
+	s.MaxDirty = 16 << 10
+	s.ProgressDBI = meta
+	s.ProgressKey = []byte("split")
+	var commits int
+	s.OnCommit = func(pos []byte) { commits++ }
+
+	// the first run is interrupted after a few commits.
+	const n = 2000
+	errStop := errors.New("stop")
+	step := splitStep(db, n)
+	err = s.Run(nil, func(txn *Txn, pos []byte) ([]byte, error) {
+		if len(pos) > 0 && binary.BigEndian.Uint64(pos) == n/2 {
+			return nil, errStop
+		}
+		return step(txn, pos)
+	})
+	if err != errStop {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if commits < 2 {
+		t.Errorf("expected several commits, got %d", commits)
+	}
+
+	var resumed uint64
+	err = s.Run(nil, func(txn *Txn, pos []byte) ([]byte, error) {
+		if resumed == 0 {
+			resumed = binary.BigEndian.Uint64(pos)
+		}
+		return step(txn, pos)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed == 0 || resumed > n/2 {
+		t.Errorf("unexpected resume position: %d", resumed)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != n {
+			t.Errorf("unexpected entries: %d (!= %d)", stat.Entries, n)
+		}
+		_, err = txn.Get(meta, s.ProgressKey)
+		if !IsNotFound(err) {
+			t.Errorf("progress was not removed: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitter_txnFull(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "split", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSplitter(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MaxDirty = 1 << 30
+
+	// pretend transactions cannot hold more than 100 steps.
+	const n = 1000
+	step := splitStep(db, n)
+	var txn0 *Txn
+	var steps int
+	err = s.Run(nil, func(txn *Txn, pos []byte) ([]byte, error) {
+		if txn != txn0 {
+			txn0, steps = txn, 0
+		}
+		steps++
+		if steps > 100 {
+			return nil, &OpError{Op: "mdbx_put", Errno: TxnFull}
+		}
+		return step(txn, pos)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MaxDirty != 1<<30 {
+		t.Errorf("MaxDirty was modified")
+	}
+
+	// a single step which does not fit is an error.
+	err = s.Run(nil, func(txn *Txn, pos []byte) ([]byte, error) {
+		return nil, &OpError{Op: "mdbx_put", Errno: TxnFull}
+	})
+	if !IsErrno(err, TxnFull) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,432 @@
+package mdbx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrCodecOrder is returned by NewTable when the ordering of an OrderedCodec
+// differs from the comparison function of the database.
+var ErrCodecOrder = errors.New("codec ordering does not match the database")
+
+// Codec converts values of type T to and from their stored form.  Encode
+// appends the encoding of v to dst.
+type Codec[T any] interface {
+	Encode(dst []byte, v T) ([]byte, error)
+	Decode(b []byte) (T, error)
+}
+
+// OrderedCodec is a Codec whose encoding preserves an ordering of T, so that
+// scanning a database yields values in that order.  Samples returns values in
+// ascending order, which NewTable uses to check that the database orders
+// their encodings the same way.
+//
+// The check is a heuristic based on the samples only: it catches a codec
+// paired with the wrong comparison function, such as little-endian integers
+// in a database using the default one, but it cannot prove that the orders
+// agree on every value.
+type OrderedCodec[T any] interface {
+	Codec[T]
+	Samples() []T
+}
+
+// Table is a typed view of a database, using codecs to convert keys of type K
+// and values of type V.  A Table holds no transaction and may be used with any
+// transaction of its environment.
+type Table[K, V any] struct {
+	dbi DBI
+	kc  Codec[K]
+	vc  Codec[V]
+}
+
+// NewTable returns a Table over database dbi.  If kc is an OrderedCodec the
+// comparison function of dbi is checked against it, as is the duplicate
+// comparison function of a DupSort database against vc.  NewTable returns
+// ErrCodecOrder if they disagree on the order of the samples of the codec.
+// Passing the check does not guarantee that they agree on every value (see
+// OrderedCodec).
+func NewTable[K, V any](txn *Txn, dbi DBI, kc Codec[K], vc Codec[V]) (*Table[K, V], error) {
+	if oc, ok := kc.(OrderedCodec[K]); ok {
+		if err := checkOrder(oc, func(a, b []byte) int { return txn.Cmp(dbi, a, b) }); err != nil {
+			return nil, err
+		}
+	}
+	flags, err := txn.Flags(dbi)
+	if err != nil {
+		return nil, err
+	}
+	if oc, ok := vc.(OrderedCodec[V]); ok && flags&DupSort != 0 {
+		if err := checkOrder(oc, func(a, b []byte) int { return txn.DCmp(dbi, a, b) }); err != nil {
+			return nil, err
+		}
+	}
+	return &Table[K, V]{dbi: dbi, kc: kc, vc: vc}, nil
+}
+
+func checkOrder[T any](c OrderedCodec[T], cmp Cmp) error {
+	var prev []byte
+	for i, v := range c.Samples() {
+		b, err := c.Encode(nil, v)
+		if err != nil {
+			return err
+		}
+		if i > 0 && cmp(prev, b) >= 0 {
+			return fmt.Errorf("%w: %x does not sort before %x", ErrCodecOrder, prev, b)
+		}
+		prev = b
+	}
+	return nil
+}
+
+// DBI returns the database of t.
+func (t *Table[K, V]) DBI() DBI {
+	return t.dbi
+}
+
+// Key returns the encoding of k, as used for Scanner bounds.
+func (t *Table[K, V]) Key(k K) ([]byte, error) {
+	return t.kc.Encode(nil, k)
+}
+
+// Get returns the value of k.  See Txn.Get.
+func (t *Table[K, V]) Get(txn *Txn, k K) (v V, err error) {
+	kb, err := t.kc.Encode(nil, k)
+	if err != nil {
+		return v, err
+	}
+	vb, err := txn.Get(t.dbi, kb)
+	if err != nil {
+		return v, err
+	}
+	return t.vc.Decode(vb)
+}
+
+// Put stores v under k.  See Txn.Put.
+func (t *Table[K, V]) Put(txn *Txn, k K, v V, flags uint) error {
+	kb, err := t.kc.Encode(nil, k)
+	if err != nil {
+		return err
+	}
+	vb, err := t.vc.Encode(nil, v)
+	if err != nil {
+		return err
+	}
+	return txn.Put(t.dbi, kb, vb, flags)
+}
+
+// Delete deletes k and all its values.  See Txn.Del.
+func (t *Table[K, V]) Delete(txn *Txn, k K) error {
+	kb, err := t.kc.Encode(nil, k)
+	if err != nil {
+		return err
+	}
+	return txn.Del(t.dbi, kb, nil)
+}
+
+// Scan returns a TableScanner decoding the items visited by s, which must
+// use a cursor of the database of t.
+func (t *Table[K, V]) Scan(s *Scanner) *TableScanner[K, V] {
+	return &TableScanner[K, V]{s: s, t: t}
+}
+
+// TableScanner is a Scanner which decodes the items it visits.
+type TableScanner[K, V any] struct {
+	s   *Scanner
+	t   *Table[K, V]
+	key K
+	val V
+	err error
+}
+
+// Scan advances s to the next item and decodes it.  Scan returns false at the
+// end of the scan or on error, including decoding errors.
+func (s *TableScanner[K, V]) Scan() bool {
+	if s.err != nil || !s.s.Scan() {
+		return false
+	}
+	s.key, s.err = s.t.kc.Decode(s.s.Key())
+	if s.err == nil {
+		s.val, s.err = s.t.vc.Decode(s.s.Val())
+	}
+	return s.err == nil
+}
+
+// Key returns the key of the current item.
+func (s *TableScanner[K, V]) Key() K {
+	return s.key
+}
+
+// Val returns the value of the current item.
+func (s *TableScanner[K, V]) Val() V {
+	return s.val
+}
+
+// Err returns the first error encountered by s.
+func (s *TableScanner[K, V]) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.s.Err()
+}
+
+// Uint64Codec encodes uint64 values in 8 bytes, big-endian, which preserves
+// their order in databases using the default comparison function.
+type Uint64Codec struct{}
+
+// Encode appends the 8 byte big-endian encoding of v to dst.
+func (Uint64Codec) Encode(dst []byte, v uint64) ([]byte, error) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(dst, b[:]...), nil
+}
+
+// Decode returns the integer encoded in b, which must be 8 bytes long.
+func (Uint64Codec) Decode(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("uint64 codec: invalid length %d", len(b))
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// Width returns 8, the length of every encoding.
+func (Uint64Codec) Width() int { return 8 }
+
+// Samples returns integers in ascending order, including the boundaries of
+// their bytes.
+func (Uint64Codec) Samples() []uint64 {
+	return []uint64{0, 1, 0xff, 0x100, 1 << 32, math.MaxUint64}
+}
+
+// FixedCodec stores byte slices of exactly Size bytes, such as DupFixed
+// values.
+type FixedCodec struct {
+	Size int
+}
+
+// Encode appends v to dst.  It fails unless v is exactly c.Size bytes long.
+func (c FixedCodec) Encode(dst []byte, v []byte) ([]byte, error) {
+	if len(v) != c.Size {
+		return nil, fmt.Errorf("fixed codec: invalid length %d (!= %d)", len(v), c.Size)
+	}
+	return append(dst, v...), nil
+}
+
+// Decode returns b, which references the memory of b, as is.  It fails unless
+// b is exactly c.Size bytes long.
+func (c FixedCodec) Decode(b []byte) ([]byte, error) {
+	if len(b) != c.Size {
+		return nil, fmt.Errorf("fixed codec: invalid length %d (!= %d)", len(b), c.Size)
+	}
+	return b, nil
+}
+
+// Width returns c.Size, the length of every encoding.
+func (c FixedCodec) Width() int { return c.Size }
+
+// Samples returns byte slices of c.Size bytes in ascending lexical order.
+func (c FixedCodec) Samples() [][]byte {
+	if c.Size == 0 {
+		return nil
+	}
+	first := make([]byte, c.Size)
+	mid := make([]byte, c.Size)
+	mid[0] = 0x80
+	last := bytes.Repeat([]byte{0xff}, c.Size)
+	samples := [][]byte{first}
+	if c.Size > 1 {
+		second := make([]byte, c.Size)
+		second[c.Size-1] = 1
+		samples = append(samples, second)
+	}
+	return append(samples, mid, last)
+}
+
+// BytesCodec stores byte slices unchanged.
+type BytesCodec struct{}
+
+// Encode appends v to dst.
+func (BytesCodec) Encode(dst []byte, v []byte) ([]byte, error) { return append(dst, v...), nil }
+
+// Decode returns b as is.
+func (BytesCodec) Decode(b []byte) ([]byte, error) { return b, nil }
+
+// Samples returns byte slices in ascending lexical order.
+func (BytesCodec) Samples() [][]byte {
+	return [][]byte{{0}, {0, 0}, {1}, {1, 0xff}, {0xff}}
+}
+
+// StringCodec stores strings unchanged.
+type StringCodec struct{}
+
+// Encode appends the bytes of v to dst.
+func (StringCodec) Encode(dst []byte, v string) ([]byte, error) { return append(dst, v...), nil }
+
+// Decode returns a copy of b as a string.
+func (StringCodec) Decode(b []byte) (string, error) { return string(b), nil }
+
+// Samples returns strings in ascending lexical order.
+func (StringCodec) Samples() []string {
+	return []string{"\x00", "A", "a", "ab", "b", "\xff"}
+}
+
+// JSONCodec stores values as JSON.  It does not preserve any ordering and is
+// meant for values.
+type JSONCodec[T any] struct{}
+
+// Encode appends the JSON encoding of v to dst.
+func (JSONCodec[T]) Encode(dst []byte, v T) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, b...), nil
+}
+
+// Decode unmarshals the JSON document b.
+func (JSONCodec[T]) Decode(b []byte) (v T, err error) {
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+// FuncCodec adapts a pair of marshaling functions, such as those of a
+// protobuf library, into a Codec.  It does not preserve any ordering.
+//
+//		codec := mdbx.FuncCodec[*pb.Block]{
+//			Marshal: func(m *pb.Block) ([]byte, error) { return proto.Marshal(m) },
+//			Unmarshal: func(b []byte) (*pb.Block, error) {
+//				m := new(pb.Block)
+//				return m, proto.Unmarshal(b, m)
+//			},
+//		}
+type FuncCodec[T any] struct {
+	Marshal   func(v T) ([]byte, error)
+	Unmarshal func(b []byte) (T, error)
+}
+
+// Encode appends the result of c.Marshal to dst.
+func (c FuncCodec[T]) Encode(dst []byte, v T) ([]byte, error) {
+	b, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, b...), nil
+}
+
+// Decode returns the result of c.Unmarshal.
+func (c FuncCodec[T]) Decode(b []byte) (T, error) {
+	return c.Unmarshal(b)
+}
+
+// Pair is a key made of two components, such as an identifier and a version.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// PairCodec encodes a Pair as the encoding of First followed by that of
+// Second.  Pairs sort by First, then by Second, in databases using the default
+// comparison function if the codecs of both components preserve their order.
+// Keys of more than two components nest pairs, e.g. a PairCodec as Second.
+//
+// If the First codec has a Width method returning a positive number, as
+// Uint64Codec and FixedCodec do, the encodings of First are stored as they
+// are.  Otherwise they are escaped and terminated so that a shorter First
+// still sorts before a longer one it is a prefix of: every zero byte is
+// followed by 0xff and the encoding is terminated by two zero bytes.
+type PairCodec[A, B any] struct {
+	First  Codec[A]
+	Second Codec[B]
+}
+
+// width returns the fixed length of the encodings of c.First, or 0.
+func (c PairCodec[A, B]) width() int {
+	if w, ok := c.First.(interface{ Width() int }); ok {
+		return w.Width()
+	}
+	return 0
+}
+
+// Encode appends the encoding of v to dst.
+func (c PairCodec[A, B]) Encode(dst []byte, v Pair[A, B]) ([]byte, error) {
+	if w := c.width(); w > 0 {
+		b, err := c.First.Encode(dst, v.First)
+		if err != nil {
+			return nil, err
+		}
+		if len(b)-len(dst) != w {
+			return nil, fmt.Errorf("pair codec: first component length %d (!= %d)", len(b)-len(dst), w)
+		}
+		return c.Second.Encode(b, v.Second)
+	}
+	first, err := c.First.Encode(nil, v.First)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range first {
+		dst = append(dst, x)
+		if x == 0 {
+			dst = append(dst, 0xff)
+		}
+	}
+	return c.Second.Encode(append(dst, 0, 0), v.Second)
+}
+
+// Decode splits b into the encodings of the components of a Pair and decodes
+// them.
+func (c PairCodec[A, B]) Decode(b []byte) (v Pair[A, B], err error) {
+	var first, rest []byte
+	if w := c.width(); w > 0 {
+		if len(b) < w {
+			return v, fmt.Errorf("pair codec: invalid length %d (< %d)", len(b), w)
+		}
+		first, rest = b[:w], b[w:]
+	} else {
+		for i := 0; ; i++ {
+			if i+1 >= len(b) {
+				return v, errors.New("pair codec: unterminated first component")
+			}
+			if b[i] != 0 {
+				first = append(first, b[i])
+				continue
+			}
+			i++
+			if b[i] == 0 {
+				rest = b[i+1:]
+				break
+			}
+			if b[i] != 0xff {
+				return v, fmt.Errorf("pair codec: invalid escape %#x", b[i])
+			}
+			first = append(first, 0)
+		}
+	}
+	if v.First, err = c.First.Decode(first); err != nil {
+		return v, err
+	}
+	v.Second, err = c.Second.Decode(rest)
+	return v, err
+}
+
+// Samples returns the pairs of the samples of the components in ascending
+// order, or nil unless the codecs of both components are OrderedCodecs.
+func (c PairCodec[A, B]) Samples() []Pair[A, B] {
+	first, ok := c.First.(OrderedCodec[A])
+	if !ok {
+		return nil
+	}
+	second, ok := c.Second.(OrderedCodec[B])
+	if !ok {
+		return nil
+	}
+	var samples []Pair[A, B]
+	for _, a := range first.Samples() {
+		for _, b := range second.Samples() {
+			samples = append(samples, Pair[A, B]{a, b})
+		}
+	}
+	return samples
+}
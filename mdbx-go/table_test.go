@@ -0,0 +1,211 @@
+package mdbx
+
+import (
+	"errors"
+	"testing"
+)
+
+type tableRecord struct {
+	Name  string
+	Count int
+}
+
+func TestTable(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "table", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.Update(func(txn *Txn) error {
+		tbl, err := NewTable[uint64, tableRecord](txn, db, Uint64Codec{}, JSONCodec[tableRecord]{})
+		if err != nil {
+			return err
+		}
+		for _, i := range []uint64{300, 1, 70000, 2} {
+			if err := tbl.Put(txn, i, tableRecord{Name: "r", Count: int(i)}, 0); err != nil {
+				return err
+			}
+		}
+		r, err := tbl.Get(txn, 70000)
+		if err != nil {
+			return err
+		}
+		if r.Count != 70000 {
+			t.Errorf("unexpected record: %+v", r)
+		}
+		if err := tbl.Delete(txn, 2); err != nil {
+			return err
+		}
+		if _, err := tbl.Get(txn, 2); !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		from, err := tbl.Key(2)
+		if err != nil {
+			return err
+		}
+		s := tbl.Scan(NewScanner(cur).Range(from, nil))
+		var keys []uint64
+		for s.Scan() {
+			if s.Val().Count != int(s.Key()) {
+				t.Errorf("unexpected item: %d=%+v", s.Key(), s.Val())
+			}
+			keys = append(keys, s.Key())
+		}
+		if len(keys) != 2 || keys[0] != 300 || keys[1] != 70000 {
+			t.Errorf("unexpected keys: %v", keys)
+		}
+		return s.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTable_order(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	rev, err := openDBI(env, "reverse", Create|ReverseKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dups, err := openDBI(env, "dups", Create|DupSort|DupFixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		_, err := NewTable[uint64, []byte](txn, rev, Uint64Codec{}, BytesCodec{})
+		if !errors.Is(err, ErrCodecOrder) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		// values are not ordered outside of DupSort databases.
+		_, err = NewTable[[]byte, string](txn, rev, FuncCodec[[]byte]{
+			Marshal:   func(b []byte) ([]byte, error) { return b, nil },
+			Unmarshal: func(b []byte) ([]byte, error) { return b, nil },
+		}, StringCodec{})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_, err = NewTable[string, []byte](txn, dups, StringCodec{}, FixedCodec{Size: 8})
+		return err
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTable_decodeError(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "table", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		if err := txn.Put(db, []byte("short"), []byte("v"), 0); err != nil {
+			return err
+		}
+		tbl, err := NewTable[uint64, []byte](txn, db, Uint64Codec{}, FixedCodec{Size: 4})
+		if err != nil {
+			return err
+		}
+		if err := tbl.Put(txn, 1, []byte("toolong"), 0); err == nil {
+			t.Errorf("expected encoding error")
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		s := tbl.Scan(NewScanner(cur))
+		if s.Scan() || s.Err() == nil {
+			t.Errorf("expected decoding error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTable_pair(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openDBI(env, "pairs", Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, err := openDBI(env, "reverse", Create|ReverseKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type key = Pair[string, Pair[uint64, string]]
+	kc := PairCodec[string, Pair[uint64, string]]{
+		First:  StringCodec{},
+		Second: PairCodec[uint64, string]{First: Uint64Codec{}, Second: StringCodec{}},
+	}
+	// in ascending order, prefixes of First and zero bytes included.
+	keys := []key{
+		{"", Pair[uint64, string]{0, ""}},
+		{"a", Pair[uint64, string]{0, "z"}},
+		{"a", Pair[uint64, string]{1, ""}},
+		{"a", Pair[uint64, string]{1 << 40, "a"}},
+		{"a\x00", Pair[uint64, string]{0, ""}},
+		{"a\x00\x00", Pair[uint64, string]{0, ""}},
+		{"a\x01", Pair[uint64, string]{0, ""}},
+		{"ab", Pair[uint64, string]{0, ""}},
+	}
+
+	err = env.Update(func(txn *Txn) error {
+		if _, err := NewTable[key, string](txn, rev, kc, StringCodec{}); !errors.Is(err, ErrCodecOrder) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		tbl, err := NewTable[key, string](txn, db, kc, StringCodec{})
+		if err != nil {
+			return err
+		}
+		for i := len(keys) - 1; i >= 0; i-- {
+			if err := tbl.Put(txn, keys[i], "v", 0); err != nil {
+				return err
+			}
+		}
+		cur, err := txn.OpenCursor(db)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		s := tbl.Scan(NewScanner(cur))
+		i := 0
+		for ; s.Scan(); i++ {
+			if i >= len(keys) || s.Key() != keys[i] {
+				t.Errorf("key %d: unexpected key %q", i, s.Key())
+			}
+		}
+		if i != len(keys) {
+			t.Errorf("%d keys scanned (!= %d)", i, len(keys))
+		}
+		return s.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range [][]byte{[]byte("a"), []byte("a\x00"), []byte("a\x00\x01")} {
+		if _, err := kc.Decode(b); err == nil {
+			t.Errorf("%q: expected a decoding error", b)
+		}
+	}
+}
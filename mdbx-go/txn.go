@@ -8,8 +8,12 @@ package mdbx
 import "C"
 
 import (
+	"context"
+	"errors"
 	"log"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 )
@@ -18,9 +22,9 @@ import (
 // Create flag must always be supplied when opening a non-root DBI for the
 // first time.
 //
-// BUG(bmatsuo):
-// MDBX_INTEGERKEY and MDBX_INTEGERDUP aren't usable. I'm not sure they would be
-// faster with the cgo bridge.  They need to be tested and benchmarked.
+// The keys of IntegerKey databases and the values of IntegerDup databases are
+// native endian uint32 or uint64 integers, all of the same size, see
+// Uint64Bytes and Cursor.GetUint64.
 const (
 	// Flags for Txn.OpenDBI.
 
@@ -30,6 +34,8 @@ const (
 	ReverseDup = C.MDBX_REVERSEDUP // Reverse duplicate values (DupSort).
 	Create     = C.MDBX_CREATE     // Create DB if not already existing.
 	DBAccede   = C.MDBX_DB_ACCEDE  // Use sorted duplicates.
+	IntegerKey = C.MDBX_INTEGERKEY // Numeric keys in native byte order.
+	IntegerDup = C.MDBX_INTEGERDUP // Numeric duplicate values in native byte order (DupSort).
 )
 
 const (
@@ -52,9 +58,24 @@ type Txn struct {
 	// If RawRead is true []byte values retrieved from Get() calls on the Txn
 	// and its cursors will point directly into the memory-mapped structure.
 	// Such slices will be readonly and must only be referenced wthin the
-	// transaction's lifetime.
+	// transaction's lifetime.  Building with the mdbxdebug tag makes any use
+	// of them after the transaction has terminated fault (see view_debug.go).
 	RawRead bool
 
+	// If AutoRawRead is true (and RawRead is false) []byte values retrieved
+	// from Get() calls on the Txn and its cursors point directly into the
+	// memory-mapped structure whenever that is safe, and are copied
+	// otherwise.  In a readonly Txn all values are safe, in a write Txn
+	// values on dirty pages are copied because later writes in the Txn may
+	// modify them (see IsDirty).  Uncopied slices have the same restrictions
+	// as those returned with RawRead.
+	//
+	// In a write Txn AutoRawRead costs one more cgo call to mdbx_is_dirty
+	// per value read, that is per Get and per value returned by GetMany or
+	// a cursor, which may outweigh the copy it saves for small values.
+	// Readonly transactions make no extra call.
+	AutoRawRead bool
+
 	// Pooled may be set to true while a Txn is stored in a sync.Pool, after
 	// Txn.Reset reset has been called and before Txn.Renew.  This will keep
 	// the Txn finalizer from unnecessarily warning the application about
@@ -64,6 +85,16 @@ type Txn struct {
 	managed  bool
 	readonly bool
 
+	// ctx is set for transactions created by ViewCtx, UpdateCtx and
+	// RunTxnCtx and is inherited by subtransactions.  cancelled is set once
+	// the transaction has been reset or aborted because ctx was done.
+	ctx       context.Context
+	cancelled bool
+
+	// broken is set by Break, possibly from another goroutine, and applied
+	// by check in the goroutine using the transaction.
+	broken int32
+
 	// The value of Txn.ID() is cached so that the cost of cgo does not have to
 	// be paid.  The id of a Txn cannot change over its life, even if it is
 	// reset/renewed
@@ -75,6 +106,18 @@ type Txn struct {
 	val  *C.MDBX_val
 
 	errLogf func(format string, v ...interface{})
+
+	// views tracks the slices referencing the memory map in mdbxdebug builds.
+	views *viewArena
+
+	// curs holds the cursors returned by Txn.Cursor.
+	curs map[DBI]*Cursor
+
+	// parent and child link a subtransaction to its parent while both are
+	// live.  savepoints is the stack of savepoints of txn, see Savepoint.
+	parent     *Txn
+	child      *Txn
+	savepoints []*Savepoint
 }
 
 // beginTxn does not lock the OS thread which is a prerequisite for creating a
@@ -102,15 +145,25 @@ func beginTxn(env *Env, parent *Txn, flags uint) (*Txn, error) {
 		}
 	} else {
 		// Because parent Txn objects cannot be used while a sub-Txn is active
-		// it is OK for them to share their C.MDBX_val objects.
+		// it is OK for them to share their C.MDBX_val objects.  Txn.check
+		// enforces this.
+		if err := parent.check("mdbx_txn_begin"); err != nil {
+			return nil, err
+		}
 		ptxn = parent._txn
 		txn.key = parent.key
 		txn.val = parent.val
+		txn.ctx = parent.ctx
+		txn.parent = parent
+		txn.inheritViews(parent)
 	}
 	ret := C.mdbx_txn_begin(env._env, ptxn, C.MDBX_txn_flags_t(flags), &txn._txn)
 	if ret != success {
 		return nil, operrno("mdbx_txn_begin", ret)
 	}
+	if parent != nil {
+		parent.child = txn
+	}
 	return txn, nil
 }
 
@@ -170,6 +223,11 @@ func (txn *Txn) runOpTerm(fn TxnOp) error {
 	if err != nil {
 		return err
 	}
+	// A readonly txn reset after its context was cancelled cannot be
+	// committed, the deferred abort releases it.
+	if err = txn.check("mdbx_txn_commit_ex"); err != nil {
+		return err
+	}
 	_, err = txn.commit()
 	return err
 }
@@ -215,6 +273,13 @@ func toDuration(seconds16dot16 C.uint32_t) time.Duration {
 }
 
 func (txn *Txn) commit() (CommitLatency, error) {
+	if len(txn.savepoints) > 0 {
+		if err := txn.release(0); err != nil {
+			txn.abort()
+			return CommitLatency{}, err
+		}
+	}
+	txn.closeCursors()
 	var _stat C.MDBX_commit_latency
 	ret := C.mdbx_txn_commit_ex(txn._txn, &_stat)
 	txn.clearTxn()
@@ -255,6 +320,8 @@ func (txn *Txn) abort() {
 	// txn.env **should** terminate all readers otherwise when it closes.
 	txn.env.closeLock.RLock()
 	if txn.env._env != nil {
+		txn.closeCursors()
+		txn.unwindSavepoints()
 		C.mdbx_txn_abort(txn._txn)
 	}
 	txn.env.closeLock.RUnlock()
@@ -266,6 +333,16 @@ func (txn *Txn) clearTxn() {
 	// Clear the C object to prevent any potential future use of the freed
 	// pointer.
 	txn._txn = nil
+	txn.endViews()
+
+	// mdbx terminates the subtransactions of txn with it.
+	if txn.child != nil {
+		txn.child.clearTxn()
+	}
+	if txn.parent != nil && txn.parent.child == txn {
+		txn.parent.child = nil
+	}
+	txn.savepoints = nil
 
 	// Clear txn.id because it no longer matches the value of txn._txn (and
 	// future calls to txn.ID() should not see the stale id).  Instead of
@@ -298,6 +375,7 @@ func (txn *Txn) Reset() {
 
 func (txn *Txn) reset() {
 	C.mdbx_txn_reset(txn._txn)
+	txn.endViews()
 }
 
 // Renew reuses a transaction that was previously reset by calling txn.Reset().
@@ -323,10 +401,100 @@ func (txn *Txn) renew() error {
 	// results in the freeing of stale pages the Txn has been holding, though
 	// this has not been confirmed in any way by bmatsuo as of 2017-02-15.
 	txn.resetID()
+	atomic.StoreInt32(&txn.broken, 0)
 
 	return operrno("mdbx_txn_renew", ret)
 }
 
+// Break marks txn as broken.  The transaction keeps its snapshot and locks
+// but any further operation on it fails with BadTxn until it is aborted.
+//
+// Break may be called on a readonly transaction from a goroutine other than
+// the one using it, which allows a stale reader to be stopped at its next
+// operation.  Break only records the request, the goroutine using txn breaks
+// it with mdbx_txn_break when it next operates on it, as mdbx transactions
+// must not be modified from other threads.  Calls must not race with the
+// termination of txn.
+//
+// Break returns a BadTxn error if txn is terminated or already broken.
+//
+// See mdbx_txn_break.
+func (txn *Txn) Break() error {
+	if txn._txn == nil || !atomic.CompareAndSwapInt32(&txn.broken, 0, breakRequested) {
+		return &OpError{Op: "mdbx_txn_break", Errno: BadTxn}
+	}
+	return nil
+}
+
+// States of Txn.broken.
+const (
+	breakRequested = 1 // Break was called
+	breakApplied   = 2 // mdbx_txn_break was called
+)
+
+// applyBreak breaks txn in mdbx on behalf of Break and returns the error of
+// op.  It must be called by the goroutine using txn.
+func (txn *Txn) applyBreak(op string) error {
+	if atomic.CompareAndSwapInt32(&txn.broken, breakRequested, breakApplied) {
+		C.mdbx_txn_break(txn._txn)
+	}
+	return &OpError{Op: op, Errno: BadTxn}
+}
+
+// Straggler returns how far txn is behind the most recent snapshot of the
+// environment.  For a readonly transaction lag is the number of transactions
+// committed since txn started.  Percent is the used fraction of the database
+// file as seen by the most recent snapshot (or by txn if it is a write
+// transaction, in which case lag is always zero).
+//
+// See mdbx_txn_straggler.
+func (txn *Txn) Straggler() (lag int, percent int, err error) {
+	var _percent C.int
+	ret := C.mdbx_txn_straggler(txn._txn, &_percent)
+	if ret < 0 {
+		return 0, 0, operrno("mdbx_txn_straggler", ret)
+	}
+	return int(ret), int(_percent), nil
+}
+
+// lagPollInterval is how often watchLag checks the lag of a transaction.
+const lagPollInterval = 50 * time.Millisecond
+
+// watchLag breaks txn once it lags more than maxLag transactions behind the
+// most recent snapshot.  The returned function stops watching and reports
+// whether txn was broken.  It must be called before txn is terminated.
+func (txn *Txn) watchLag(maxLag int) (stop func() bool) {
+	var broken bool
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(lagPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			lag, _, err := txn.Straggler()
+			if err != nil || lag <= maxLag {
+				continue
+			}
+			// Break fails if fn already broke txn, which is then not
+			// retried.
+			broken = txn.Break() == nil
+			return
+		}
+	}()
+	return func() bool {
+		close(done)
+		wg.Wait()
+		return broken
+	}
+}
+
 // OpenDBI opens a named database in the environment.  An error is returned if
 // name is empty.  The DBI returned by OpenDBI can be used in other
 // transactions but not before Txn has terminated.
@@ -338,6 +506,13 @@ func (txn *Txn) renew() error {
 // that names cannot contain null bytes themselves. OpenDBI does not check for
 // null bytes in the name argument.
 //
+// Cmp and dcmp, if not nil, are the comparison functions of the keys and the
+// duplicate values of the database (see LookupCmp and RegisterCmp).  Once a
+// database is opened with a comparison function, opening it with another one
+// fails with EINVAL until the environment is closed.  Mdbx does not store the
+// comparison functions in the database, see Schema for a check across
+// processes.
+//
 // See mdbx_dbi_open.
 func (txn *Txn) OpenDBI(name string, flags uint, cmp, dcmp CmpFunc) (DBI, error) {
 	cname := C.CString(name)
@@ -360,11 +535,35 @@ func (txn *Txn) CreateDBI(name string) (DBI, error) {
 
 // Flags returns the database flags for handle dbi.
 func (txn *Txn) Flags(dbi DBI) (uint, error) {
+	if err := txn.check("mdbx_dbi_flags"); err != nil {
+		return 0, err
+	}
 	var cflags C.uint
 	ret := C.mdbx_dbi_flags(txn._txn, C.MDBX_dbi(dbi), &cflags)
 	return uint(cflags), operrno("mdbx_dbi_flags", ret)
 }
 
+// State bits of a DBI returned by Txn.FlagsEx.
+const (
+	DBIDirty = C.MDBX_DBI_DIRTY // DB was written in this txn.
+	DBIStale = C.MDBX_DBI_STALE // Named-DB record is older than txnID.
+	DBIFresh = C.MDBX_DBI_FRESH // Named-DB handle opened in this txn.
+	DBICreat = C.MDBX_DBI_CREAT // Named-DB handle created in this txn.
+)
+
+// FlagsEx returns the flags of database dbi along with its state in txn (see
+// DBIDirty, etc).
+//
+// See mdbx_dbi_flags_ex.
+func (txn *Txn) FlagsEx(dbi DBI) (flags, state uint, err error) {
+	if err := txn.check("mdbx_dbi_flags_ex"); err != nil {
+		return 0, 0, err
+	}
+	var cflags, cstate C.uint
+	ret := C.mdbx_dbi_flags_ex(txn._txn, C.MDBX_dbi(dbi), &cflags, &cstate)
+	return uint(cflags), uint(cstate), operrno("mdbx_dbi_flags_ex", ret)
+}
+
 // OpenRoot opens the root database.  OpenRoot behaves similarly to OpenDBI but
 // does not require env.SetMaxDBs() to be called beforehand.  And, OpenRoot can
 // be called without flags in a View transaction.
@@ -380,12 +579,18 @@ type Cmp func(k1, k2 []byte) int
 // applications are expected to handle any error encountered opening a
 // database.
 func (txn *Txn) openDBI(cname *C.char, flags uint, cmp, dcmp *C.MDBX_cmp_func) (DBI, error) {
+	if err := txn.check("mdbx_dbi_open"); err != nil {
+		return 0, err
+	}
 	var dbi C.MDBX_dbi
 	ret := C.mdbx_dbi_open_ex(txn._txn, cname, C.MDBX_db_flags_t(flags), &dbi, cmp, dcmp)
 	return DBI(dbi), operrno("mdbx_dbi_open", ret)
 }
 
 func (txn *Txn) openDBISimple(cname *C.char, flags uint) (DBI, error) {
+	if err := txn.check("mdbx_dbi_open"); err != nil {
+		return 0, err
+	}
 	var dbi C.MDBX_dbi
 	ret := C.mdbx_dbi_open(txn._txn, cname, C.MDBX_db_flags_t(flags), &dbi)
 	return DBI(dbi), operrno("mdbx_dbi_open", ret)
@@ -432,6 +637,9 @@ type TxInfo struct {
 //  if corresponding fields are not needed.
 //  See description of \ref MDBX_txn_info.
 func (txn *Txn) Info(scanRlt bool) (*TxInfo, error) {
+	if err := txn.check("mdbx_txn_info"); err != nil {
+		return nil, err
+	}
 	var _stat C.MDBX_txn_info
 	ret := C.mdbx_txn_info(txn._txn, &_stat, C.bool(scanRlt))
 	if ret != success {
@@ -450,6 +658,9 @@ func (txn *Txn) Info(scanRlt bool) (*TxInfo, error) {
 }
 
 func (txn *Txn) StatDBI(dbi DBI) (*Stat, error) {
+	if err := txn.check("mdbx_stat"); err != nil {
+		return nil, err
+	}
 	var _stat C.MDBX_stat
 	ret := C.mdbx_dbi_stat(txn._txn, C.MDBX_dbi(dbi), &_stat, C.size_t(unsafe.Sizeof(_stat)))
 	if ret != success {
@@ -469,6 +680,9 @@ func (txn *Txn) StatDBI(dbi DBI) (*Stat, error) {
 //
 // See mdbx_drop.
 func (txn *Txn) Drop(dbi DBI, del bool) error {
+	if err := txn.check("mdbx_drop"); err != nil {
+		return err
+	}
 	ret := C.mdbx_drop(txn._txn, C.MDBX_dbi(dbi), C.bool(del))
 	return operrno("mdbx_drop", ret)
 }
@@ -484,6 +698,9 @@ func (txn *Txn) Drop(dbi DBI, del bool) error {
 //
 // Any call to Abort, Commit, Renew, or Reset on a Txn created by Sub will
 // panic.
+//
+// While the subtransaction is live txn, and its cursors, cannot be used:
+// their operations fail with ErrTxnChildActive.
 func (txn *Txn) Sub(fn TxnOp) error {
 	// As of 0.9.14 Readonly is the only Txn flag and readonly subtransactions
 	// don't make sense.
@@ -501,23 +718,79 @@ func (txn *Txn) subFlag(flags uint, fn TxnOp) error {
 	if err != nil {
 		return err
 	}
+	if err = sub.check("mdbx_txn_commit_ex"); err != nil {
+		return err
+	}
 	_, err = sub.commit()
 	return err
 }
 
+// ErrTxnChildActive is returned by the operations of a transaction, or of its
+// cursors, while a subtransaction of it is live.
+var ErrTxnChildActive = errors.New("transaction has a live subtransaction")
+
+// check returns an error if txn cannot be used for op, because it has a live
+// subtransaction or its context is done (see checkCtx).  Txn may be nil, as
+// it is for closed cursors.
+func (txn *Txn) check(op string) error {
+	if txn != nil {
+		if txn.child != nil {
+			return &OpError{Op: op, Errno: ErrTxnChildActive}
+		}
+		if atomic.LoadInt32(&txn.broken) != 0 {
+			return txn.applyBreak(op)
+		}
+	}
+	return txn.checkCtx(op)
+}
+
 func (txn *Txn) bytes(val *C.MDBX_val) []byte {
 	if txn.RawRead {
-		return getBytes(val)
+		return txn.viewBytes(val)
+	}
+	if txn.AutoRawRead && (txn.readonly || !txn.isDirty(val.iov_base)) {
+		return txn.viewBytes(val)
 	}
 	return getBytesCopy(val)
 }
 
+// IsDirty returns true if b points at data on a page modified by txn.  Such
+// data may be changed by further writes in txn, while data on clean pages is
+// stable for the lifetime of txn.  It is only meaningful for slices returned
+// with RawRead.  Only the page holding b[0] is examined, so a subslice gives
+// the same answer as long as it starts on that page, while a subslice of a
+// value spanning several overflow pages which starts on a later page does
+// not.  IsDirty may return false positives, but never false negatives.
+//
+// See mdbx_is_dirty.
+func (txn *Txn) IsDirty(b []byte) (bool, error) {
+	if len(b) == 0 {
+		return false, nil
+	}
+	ret := C.mdbx_is_dirty(txn._txn, unsafe.Pointer(&b[0]))
+	switch ret {
+	case C.MDBX_RESULT_TRUE:
+		return true, nil
+	case C.MDBX_RESULT_FALSE:
+		return false, nil
+	}
+	return false, operrno("mdbx_is_dirty", ret)
+}
+
+// isDirty treats failures as dirty because copying is always safe.
+func (txn *Txn) isDirty(p unsafe.Pointer) bool {
+	return C.mdbx_is_dirty(txn._txn, p) != C.MDBX_RESULT_FALSE
+}
+
 // Get retrieves items from database dbi.  If txn.RawRead is true the slice
 // returned by Get references a readonly section of memory that must not be
 // accessed after txn has terminated.
 //
 // See mdbx_get.
 func (txn *Txn) Get(dbi DBI, key []byte) ([]byte, error) {
+	if err := txn.check("mdbx_get"); err != nil {
+		return nil, err
+	}
 	kdata, kn := valBytes(key)
 	ret := C.mdbxgo_get(
 		txn._txn, C.MDBX_dbi(dbi),
@@ -534,6 +807,85 @@ func (txn *Txn) Get(dbi DBI, key []byte) ([]byte, error) {
 	return b, nil
 }
 
+// GetInto is like Get, but copies the value into dst, growing it if needed,
+// and returns dst[:len(value)].  GetInto always copies, whatever the value of
+// RawRead, so the result never references the memory map.  When dst is large
+// enough GetInto does not allocate, so a caller reusing its buffer reads
+// without producing garbage.
+//
+// See mdbx_get.
+func (txn *Txn) GetInto(dbi DBI, key, dst []byte) ([]byte, error) {
+	if err := txn.check("mdbx_get"); err != nil {
+		return nil, err
+	}
+	kdata, kn := valBytes(key)
+	ret := C.mdbxgo_get(
+		txn._txn, C.MDBX_dbi(dbi),
+		(*C.char)(unsafe.Pointer(&kdata[0])), C.size_t(kn),
+		txn.val,
+	)
+	err := operrno("mdbx_get", ret)
+	if err != nil {
+		*txn.val = C.MDBX_val{}
+		return nil, err
+	}
+	b := append(dst[:0], getBytes(txn.val)...)
+	*txn.val = C.MDBX_val{}
+	return b, nil
+}
+
+// GetMany retrieves the values of keys from database dbi using a single cgo
+// call instead of one per key.  The value of keys[i] is returned at index i,
+// or nil if keys[i] does not exist in the database.  The returned slices
+// follow the same rules as those returned by Get.
+//
+// See mdbx_get.
+func (txn *Txn) GetMany(dbi DBI, keys [][]byte) ([][]byte, error) {
+	if err := txn.check("mdbx_get"); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var size int
+	for _, k := range keys {
+		size += len(k)
+	}
+	// A trailing byte keeps kdata from being empty so it can be passed by
+	// pointer.
+	kdata := make([]byte, 0, size+1)
+	klens := make([]C.size_t, len(keys))
+	for i, k := range keys {
+		kdata = append(kdata, k...)
+		klens[i] = C.size_t(len(k))
+	}
+	kdata = append(kdata, 0)
+
+	cvals := make([]C.MDBX_val, len(keys))
+	found := make([]byte, len(keys))
+	ret := C.mdbxgo_get_many(
+		txn._txn, C.MDBX_dbi(dbi),
+		(*C.char)(unsafe.Pointer(&kdata[0])), &klens[0], C.size_t(len(keys)),
+		&cvals[0], (*C.char)(unsafe.Pointer(&found[0])),
+	)
+	if err := operrno("mdbx_get", ret); err != nil {
+		return nil, err
+	}
+	vals := make([][]byte, len(keys))
+	for i := range cvals {
+		if found[i] == 0 {
+			continue
+		}
+		if cvals[i].iov_len == 0 {
+			vals[i] = []byte{}
+			continue
+		}
+		vals[i] = txn.bytes(&cvals[i])
+	}
+	return vals, nil
+}
+
 func (txn *Txn) putNilKey(dbi DBI, flags uint) error {
 	// mdbx_put with an empty key will always fail
 	ret := C.mdbxgo_put2(txn._txn, C.MDBX_dbi(dbi), nil, 0, nil, 0, C.MDBX_put_flags_t(flags))
@@ -544,6 +896,9 @@ func (txn *Txn) putNilKey(dbi DBI, flags uint) error {
 //
 // See mdbx_put.
 func (txn *Txn) Put(dbi DBI, key []byte, val []byte, flags uint) error {
+	if err := txn.check("mdbx_put"); err != nil {
+		return err
+	}
 	kn := len(key)
 	if kn == 0 {
 		return txn.putNilKey(dbi, flags)
@@ -566,6 +921,9 @@ func (txn *Txn) Put(dbi DBI, key []byte, val []byte, flags uint) error {
 // avoiding a memcopy.  The returned byte slice is only valid in txn's thread,
 // before it has terminated.
 func (txn *Txn) PutReserve(dbi DBI, key []byte, n int, flags uint) ([]byte, error) {
+	if err := txn.check("mdbx_put"); err != nil {
+		return nil, err
+	}
 	if len(key) == 0 {
 		return nil, txn.putNilKey(dbi, flags)
 	}
@@ -591,6 +949,9 @@ func (txn *Txn) PutReserve(dbi DBI, key []byte, n int, flags uint) ([]byte, erro
 //
 // See mdbx_del.
 func (txn *Txn) Del(dbi DBI, key, val []byte) error {
+	if err := txn.check("mdbx_del"); err != nil {
+		return err
+	}
 	kdata, kn := valBytes(key)
 	if val == nil {
 		ret := C.mdbxgo_del(
@@ -613,6 +974,9 @@ func (txn *Txn) Del(dbi DBI, key, val []byte) error {
 //
 // See mdbx_cursor_open.
 func (txn *Txn) OpenCursor(dbi DBI) (*Cursor, error) {
+	if err := txn.check("mdbx_cursor_open"); err != nil {
+		return nil, err
+	}
 	cur, err := openCursor(txn, dbi)
 	if cur != nil && txn.readonly {
 		runtime.SetFinalizer(cur, (*Cursor).close)
@@ -620,6 +984,59 @@ func (txn *Txn) OpenCursor(dbi DBI) (*Cursor, error) {
 	return cur, err
 }
 
+// Cursor returns a cursor on database dbi owned by txn.  The cursor is opened
+// by the first call for dbi, later calls return the same cursor, unpositioned
+// as if it had just been opened.  A Txn therefore holds a single cursor per
+// database and code using Cursor must not expect the position of one use to
+// survive another.
+//
+// The cursors are closed when txn is committed or aborted, before mdbx
+// terminates the transaction, and must not be closed by the caller.  The
+// cursors of a readonly txn survive Reset and Renew.
+func (txn *Txn) Cursor(dbi DBI) (*Cursor, error) {
+	if err := txn.check("mdbx_cursor_open"); err != nil {
+		return nil, err
+	}
+	if cur, ok := txn.curs[dbi]; ok {
+		if cur._c != nil && cur.Renew(txn) == nil {
+			return cur, nil
+		}
+		cur.close()
+		delete(txn.curs, dbi)
+	}
+	cur, err := openCursor(txn, dbi)
+	if err != nil {
+		return nil, err
+	}
+	if txn.curs == nil {
+		txn.curs = make(map[DBI]*Cursor)
+	}
+	txn.curs[dbi] = cur
+	return cur, nil
+}
+
+// closeCursors closes the cursors returned by Cursor, including those of the
+// savepoints and subtransactions of txn.  It is called while txn is still
+// live, so that every cursor is released by mdbx_cursor_close, which
+// Cursor.close does not call for the cursors of a terminated write
+// transaction.
+func (txn *Txn) closeCursors() {
+	if txn.child != nil {
+		txn.child.closeCursors()
+	}
+	closeCursorMap(txn.curs)
+	for _, sp := range txn.savepoints {
+		closeCursorMap(sp.curs)
+	}
+}
+
+func closeCursorMap(curs map[DBI]*Cursor) {
+	for dbi, cur := range curs {
+		cur.close()
+		delete(curs, dbi)
+	}
+}
+
 func (txn *Txn) errf(format string, v ...interface{}) {
 	if txn.errLogf != nil {
 		txn.errLogf(format, v...)
@@ -650,6 +1067,7 @@ type TxnOp func(txn *Txn) error
 
 type CmpFunc *C.MDBX_cmp_func
 
+// GetCmpExcludeSuffix32 returns CmpExcludeSuffix(32).
 func (txn *Txn) GetCmpExcludeSuffix32() CmpFunc {
 	return C.mdbxgo_get_cmp_exclude_suffix32()
 }
@@ -691,6 +1109,9 @@ func (txn *Txn) DCmp(dbi DBI, a []byte, b []byte) int {
 }
 
 func (txn *Txn) Sequence(dbi DBI, increment uint64) (uint64, error) {
+	if err := txn.check("mdbx_dbi_sequence"); err != nil {
+		return 0, err
+	}
 	var res C.uint64_t
 	ret := C.mdbx_dbi_sequence(txn._txn, C.MDBX_dbi(dbi), &res, C.uint64_t(increment))
 	if ret != 0 {
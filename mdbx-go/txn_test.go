@@ -6,6 +6,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
+	"reflect"
 	"runtime"
 	"syscall"
 	"testing"
@@ -305,6 +306,46 @@ func TestTexn_Put_emptyValue(t *testing.T) {
 	}
 }
 
+func TestTxn_GetMany(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) error {
+		for _, k := range []string{"a", "b", "d"} {
+			if err := txn.Put(db, []byte(k), []byte("val"+k), 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		vals, err := txn.GetMany(db, [][]byte{[]byte("d"), []byte("c"), []byte("a"), nil})
+		if err != nil {
+			return err
+		}
+		want := [][]byte{[]byte("vald"), nil, []byte("vala"), nil}
+		if !reflect.DeepEqual(vals, want) {
+			t.Errorf("unexpected values: %q (!= %q)", vals, want)
+		}
+		vals, err = txn.GetMany(db, nil)
+		if err != nil || vals != nil {
+			t.Errorf("unexpected result for no keys: %q, %v", vals, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
 func TestTxn_PutReserve(t *testing.T) {
 	env := setup(t)
 	defer clean(env, t)
@@ -1043,6 +1084,240 @@ func TestSequence(t *testing.T) {
 	}
 }
 
+func TestTxn_Straggler(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Abort()
+
+	lag, _, err := txn.Straggler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag != 0 {
+		t.Errorf("unexpected lag: %d", lag)
+	}
+
+	for i := 0; i < 3; i++ {
+		err = env.Update(func(txn *Txn) (err error) {
+			return txn.Put(db, []byte("k"), []byte{byte(i)}, 0)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lag, percent, err := txn.Straggler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lag != 3 {
+		t.Errorf("unexpected lag: %d (!= %d)", lag, 3)
+	}
+	if percent < 0 || percent > 100 {
+		t.Errorf("unexpected percent: %d", percent)
+	}
+}
+
+func TestTxn_Break(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) (err error) {
+		return txn.Put(db, []byte("k"), []byte("v"), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) (err error) {
+		_, err = txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		err = txn.Break()
+		if err != nil {
+			return err
+		}
+		if err := txn.Break(); !IsErrno(err, BadTxn) {
+			t.Errorf("second break: unexpected error: %v", err)
+		}
+		_, err = txn.Get(db, []byte("k"))
+		if !IsErrno(err, BadTxn) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return nil
+	})
+	// the broken txn is not committed.
+	if !IsErrno(err, BadTxn) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn.Abort()
+	if err := txn.Break(); !IsErrno(err, BadTxn) {
+		t.Errorf("break of a terminated txn: unexpected error: %v", err)
+	}
+}
+
+func TestTxn_Break_otherGoroutine(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) (err error) {
+		return txn.Put(db, []byte("k"), []byte("v"), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) (err error) {
+		done := make(chan error)
+		go func() { done <- txn.Break() }()
+		if err := <-done; err != nil {
+			return err
+		}
+		for i := 0; i < 2; i++ {
+			_, err = txn.Get(db, []byte("k"))
+			if !IsErrno(err, BadTxn) {
+				t.Errorf("get %d: unexpected error: %v", i, err)
+			}
+		}
+		return nil
+	})
+	// the broken txn is not committed.
+	if !IsErrno(err, BadTxn) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTxn_IsDirty(t *testing.T) {
+	if debugViews {
+		t.Skip("slices do not point into the memory map in mdbxdebug builds")
+	}
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) (err error) {
+		return txn.Put(db, []byte("k0"), []byte("v0"), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.Update(func(txn *Txn) (err error) {
+		txn.RawRead = true
+		v, err := txn.Get(db, []byte("k0"))
+		if err != nil {
+			return err
+		}
+		dirty, err := txn.IsDirty(v)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			t.Errorf("value on a clean page reported dirty")
+		}
+
+		err = txn.Put(db, []byte("k1"), []byte("v1"), 0)
+		if err != nil {
+			return err
+		}
+		v, err = txn.Get(db, []byte("k0"))
+		if err != nil {
+			return err
+		}
+		dirty, err = txn.IsDirty(v)
+		if err != nil {
+			return err
+		}
+		if !dirty {
+			t.Errorf("value on a dirty page reported clean")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTxn_AutoRawRead(t *testing.T) {
+	if debugViews {
+		t.Skip("slices do not point into the memory map in mdbxdebug builds")
+	}
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = env.Update(func(txn *Txn) (err error) {
+		return txn.Put(db, []byte("k0"), []byte("v0"), 0)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = env.Update(func(txn *Txn) (err error) {
+		txn.AutoRawRead = true
+
+		// IsDirty reports false only for clean pages inside the map, so a
+		// value which was copied onto the Go heap is reported dirty.
+		v, err := txn.Get(db, []byte("k0"))
+		if err != nil {
+			return err
+		}
+		if dirty, _ := txn.IsDirty(v); dirty {
+			t.Errorf("value on a clean page was copied")
+		}
+
+		err = txn.Put(db, []byte("k0"), []byte("v1"), 0)
+		if err != nil {
+			return err
+		}
+		v, err = txn.Get(db, []byte("k0"))
+		if err != nil {
+			return err
+		}
+		err = txn.Put(db, []byte("k0"), []byte("v2"), 0)
+		if err != nil {
+			return err
+		}
+		if string(v) != "v1" {
+			t.Errorf("value on a dirty page was not copied: %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
 func BenchmarkTxn_abort(b *testing.B) {
 	env := setup(b)
 	path, err := env.Path()
@@ -1259,6 +1534,209 @@ func BenchmarkTxn_Put_append(b *testing.B) {
 	}
 }
 
+func BenchmarkTxn_Put_append_batch(b *testing.B) {
+	env := setup(b)
+	path, err := env.Path()
+	if err != nil {
+		_ = env.Close()
+		b.Error(err)
+		return
+	}
+	defer os.RemoveAll(path)
+	defer env.Close()
+	err = env.SetGeometry(-1, -1, 1<<30, -1, -1, 4096)
+	if err != nil {
+		b.Error(err)
+		return
+	}
+
+	var db DBI
+
+	err = env.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenRoot(0)
+		return err
+	})
+	if err != nil {
+		b.Errorf("dbi: %v", err)
+		return
+	}
+
+	// the same batch size as insertBatchMdbx.
+	const batchSize = 1000
+	keys := make([][]byte, batchSize)
+	for i := range keys {
+		keys[i] = make([]byte, 8)
+	}
+
+	for _, batch := range []bool{false, true} {
+		b.Run(fmt.Sprintf("batch=%v", batch), func(b *testing.B) {
+			err = env.Update(func(txn *Txn) (err error) {
+				cur, err := txn.OpenCursor(db)
+				if err != nil {
+					return err
+				}
+				defer cur.Close()
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i += batchSize {
+					n := batchSize
+					if b.N-i < n {
+						n = b.N - i
+					}
+					for j := 0; j < n; j++ {
+						binary.BigEndian.PutUint64(keys[j], uint64(i+j))
+					}
+					if batch {
+						_, err = cur.PutBatch(keys[:n], keys[:n], Append)
+						if err != nil {
+							return err
+						}
+						continue
+					}
+					for j := 0; j < n; j++ {
+						err = cur.Put(keys[j], keys[j], Append)
+						if err != nil {
+							return err
+						}
+					}
+				}
+
+				b.StopTimer()
+				defer b.StartTimer()
+
+				return txn.Drop(db, false)
+			})
+			if err != nil {
+				b.Errorf("put: %v", err)
+			}
+		})
+	}
+}
+
+func BenchmarkTxn_GetMany(b *testing.B) {
+	env := setup(b)
+	path, err := env.Path()
+	if err != nil {
+		_ = env.Close()
+		b.Error(err)
+		return
+	}
+	defer os.RemoveAll(path)
+	defer env.Close()
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		b.Errorf("dbi: %v", err)
+		return
+	}
+	var keys [][]byte
+	err = env.Update(func(txn *Txn) (err error) {
+		for i := 0; i < 1000; i++ {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			keys = append(keys, k[:])
+			err = txn.Put(db, k[:], k[:], Append)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Errorf("put: %v", err)
+		return
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		err = env.View(func(txn *Txn) (err error) {
+			txn.RawRead = true
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, k := range keys {
+					if _, err = txn.Get(db, k); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Errorf("get: %v", err)
+		}
+	})
+	b.Run("GetMany", func(b *testing.B) {
+		err = env.View(func(txn *Txn) (err error) {
+			txn.RawRead = true
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err = txn.GetMany(db, keys); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			b.Errorf("get: %v", err)
+		}
+	})
+}
+
+func BenchmarkTxn_Get_AutoRawRead(b *testing.B) {
+	env := setup(b)
+	path, err := env.Path()
+	if err != nil {
+		_ = env.Close()
+		b.Error(err)
+		return
+	}
+	defer os.RemoveAll(path)
+	defer env.Close()
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		b.Errorf("dbi: %v", err)
+		return
+	}
+	var keys [][]byte
+	err = env.Update(func(txn *Txn) (err error) {
+		for i := 0; i < 1000; i++ {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			keys = append(keys, k[:])
+			err = txn.Put(db, k[:], bytes.Repeat(k[:], 16), Append)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Errorf("put: %v", err)
+		return
+	}
+
+	for _, auto := range []bool{false, true} {
+		b.Run(fmt.Sprintf("auto=%v", auto), func(b *testing.B) {
+			err = env.Update(func(txn *Txn) (err error) {
+				txn.AutoRawRead = auto
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, err = txn.Get(db, keys[i%len(keys)])
+					if err != nil {
+						return err
+					}
+				}
+				b.StopTimer()
+				return nil
+			})
+			if err != nil {
+				b.Errorf("get: %v", err)
+			}
+		})
+	}
+}
+
 func BenchmarkTxn_Put_append_noflag(b *testing.B) {
 	env := setup(b)
 	path, err := env.Path()
@@ -1341,3 +1819,185 @@ func TestNameToNano(t *testing.T) {
 		t.Error("unexpected result")
 	}
 }
+
+func TestTxn_GetInto(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	err := env.Update(func(txn *Txn) error {
+		db, err := txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		if err := txn.Put(db, []byte("k"), []byte("value"), 0); err != nil {
+			return err
+		}
+
+		buf := make([]byte, 0, 3)
+		v, err := txn.GetInto(db, []byte("k"), buf)
+		if err != nil {
+			return err
+		}
+		if string(v) != "value" {
+			t.Errorf("unexpected value: %q", v)
+		}
+		if _, err := txn.GetInto(db, []byte("missing"), buf); !IsNotFound(err) {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		buf, key := v, []byte("k")
+		allocs := testing.AllocsPerRun(100, func() {
+			buf, err = txn.GetInto(db, key, buf)
+		})
+		if err != nil {
+			return err
+		}
+		if allocs != 0 {
+			t.Errorf("unexpected allocations per GetInto: %v", allocs)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkTxn_GetInto(b *testing.B) {
+	env := setup(b)
+	defer clean(env, b)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var keys [][]byte
+	err = env.Update(func(txn *Txn) (err error) {
+		for i := 0; i < 1000; i++ {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			keys = append(keys, k[:])
+			err = txn.Put(db, k[:], bytes.Repeat(k[:], 16), Append)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = env.View(func(txn *Txn) (err error) {
+		var buf []byte
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			buf, err = txn.GetInto(db, keys[i%len(keys)], buf)
+			if err != nil {
+				return err
+			}
+		}
+		b.StopTimer()
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+}
+
+func TestTxn_Cursor(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var cur1 *Cursor
+	err := env.Update(func(txn *Txn) error {
+		db1, err := txn.OpenDBI("db1", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		db2, err := txn.OpenDBI("db2", Create, nil, nil)
+		if err != nil {
+			return err
+		}
+		cur1, err = txn.Cursor(db1)
+		if err != nil {
+			return err
+		}
+		if err := cur1.Put([]byte("k"), []byte("v"), 0); err != nil {
+			return err
+		}
+		if _, _, err := cur1.Get(nil, nil, GetCurrent); err != nil {
+			return err
+		}
+		cur2, err := txn.Cursor(db2)
+		if err != nil {
+			return err
+		}
+		if cur2 == cur1 || cur2.DBI() != db2 {
+			t.Errorf("unexpected cursor for db2")
+		}
+
+		// a checked out cursor is unpositioned.
+		cur, err := txn.Cursor(db1)
+		if err != nil {
+			return err
+		}
+		if cur != cur1 {
+			t.Errorf("cursor was not reused")
+		}
+		if _, _, err := cur.Get(nil, nil, GetCurrent); err == nil {
+			t.Errorf("cursor is still positioned")
+		}
+		if _, _, err := cur.Get(nil, nil, First); err != nil {
+			return err
+		}
+
+		return txn.Sub(func(sub *Txn) error {
+			cur, err := sub.Cursor(db1)
+			if err != nil {
+				return err
+			}
+			if cur == cur1 {
+				t.Errorf("subtransaction shares the cursor of its parent")
+			}
+			return cur.Put([]byte("k2"), []byte("v"), 0)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur1.Txn() != nil {
+		t.Errorf("cursor was not closed with its transaction")
+	}
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Abort()
+	db1, err := txn.OpenDBISimple("db1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		cur, err := txn.Cursor(db1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		s := NewScanner(cur)
+		for s.Scan() {
+			n++
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("unexpected number of items: %d", n)
+		}
+		txn.Reset()
+		if err := txn.Renew(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
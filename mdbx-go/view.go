@@ -0,0 +1,24 @@
+//go:build !mdbxdebug || !linux
+// +build !mdbxdebug !linux
+
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+// debugViews is true in mdbxdebug builds.
+const debugViews = false
+
+// viewArena is only used when the package is built with the mdbxdebug tag,
+// see view_debug.go.
+type viewArena struct{}
+
+func (txn *Txn) inheritViews(parent *Txn) {}
+
+func (txn *Txn) viewBytes(val *C.MDBX_val) []byte {
+	return getBytes(val)
+}
+
+func (txn *Txn) endViews() {}
@@ -0,0 +1,89 @@
+//go:build mdbxdebug && linux
+// +build mdbxdebug,linux
+
+package mdbx
+
+/*
+#include "mdbxgo.h"
+*/
+import "C"
+
+import (
+	"syscall"
+)
+
+// Built with the mdbxdebug tag the package checks the lifetime of the slices
+// which reference the memory map (see Txn.RawRead).  Instead of pointing into
+// the memory map those slices are copies placed in memory mapped by the
+// package, which becomes inaccessible (PROT_NONE) once the transaction that
+// returned them is committed, aborted or reset.  Any later use of them faults
+// at once, with a stack trace pointing at the culprit, rather than reading
+// whatever the database has since written there.  Slices returned in a
+// subtransaction are valid until its top-level transaction terminates.
+//
+// The copies make reads slower and the protected memory is never reused, so
+// the tag is meant for tests only.  Txn.IsDirty cannot tell whether a copy
+// came from a dirty page.
+
+// debugViews is true in mdbxdebug builds.
+const debugViews = true
+
+// viewChunkSize is the minimum size of the memory mapped for copies.
+const viewChunkSize = 64 << 10
+
+// viewArena holds the copies made for a top-level transaction and its
+// subtransactions.
+type viewArena struct {
+	owner  *Txn
+	chunks [][]byte
+	free   []byte
+}
+
+func (txn *Txn) inheritViews(parent *Txn) {
+	if parent.views == nil {
+		parent.views = &viewArena{owner: parent}
+	}
+	txn.views = parent.views
+}
+
+func (txn *Txn) viewBytes(val *C.MDBX_val) []byte {
+	if txn.views == nil {
+		txn.views = &viewArena{owner: txn}
+	}
+	return txn.views.copy(getBytes(val))
+}
+
+func (txn *Txn) endViews() {
+	a := txn.views
+	txn.views = nil
+	if a == nil || a.owner != txn {
+		return
+	}
+	for _, chunk := range a.chunks {
+		// Release the pages before revoking access to them.
+		_ = syscall.Madvise(chunk, syscall.MADV_DONTNEED)
+		if err := syscall.Mprotect(chunk, syscall.PROT_NONE); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (a *viewArena) copy(b []byte) []byte {
+	if len(b) > len(a.free) {
+		size := viewChunkSize
+		if len(b) > size {
+			page := syscall.Getpagesize()
+			size = (len(b) + page - 1) / page * page
+		}
+		chunk, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+		if err != nil {
+			panic(err)
+		}
+		a.chunks = append(a.chunks, chunk)
+		a.free = chunk
+	}
+	n := copy(a.free, b)
+	p := a.free[:n:n]
+	a.free = a.free[n:]
+	return p
+}
@@ -0,0 +1,92 @@
+//go:build mdbxdebug && linux
+// +build mdbxdebug,linux
+
+package mdbx
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+var viewSink byte
+
+// mustFault calls fn, which must fault on a protected view.
+func mustFault(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: use of a view after the end of its transaction did not fault", name)
+		}
+	}()
+	fn()
+}
+
+func TestView_debug(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	var db DBI
+	var inUpdate, inSub []byte
+	err := env.Update(func(txn *Txn) (err error) {
+		txn.RawRead = true
+		db, err = txn.OpenRoot(0)
+		if err != nil {
+			return err
+		}
+		if err := txn.Put(db, []byte("k"), []byte("value"), 0); err != nil {
+			return err
+		}
+		inUpdate, err = txn.Get(db, []byte("k"))
+		if err != nil {
+			return err
+		}
+		err = txn.Sub(func(sub *Txn) (err error) {
+			sub.RawRead = true
+			inSub, err = sub.Get(db, []byte("k"))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		// views of a subtransaction outlive it.
+		if string(inSub) != "value" {
+			t.Errorf("unexpected value: %q", inSub)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustFault(t, "update", func() { viewSink = inUpdate[0] })
+	mustFault(t, "sub", func() { viewSink = inSub[0] })
+
+	txn, err := env.BeginTxn(nil, Readonly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer txn.Abort()
+	txn.RawRead = true
+	cur, err := txn.OpenCursor(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, v, err := cur.Get(nil, nil, First)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(k) != "k" || string(v) != "value" {
+		t.Errorf("unexpected item: %q %q", k, v)
+	}
+	cur.Close()
+	txn.Reset()
+	mustFault(t, "reset", func() { viewSink = v[0] })
+
+	if err := txn.Renew(); err != nil {
+		t.Fatal(err)
+	}
+	v, err = txn.Get(db, []byte("k"))
+	if err != nil || string(v) != "value" {
+		t.Errorf("unexpected value after renew: %q %v", v, err)
+	}
+}
@@ -0,0 +1,137 @@
+package mdbx
+
+/*
+#include <stdlib.h>
+#include <stdio.h>
+#include "mdbxgo.h"
+*/
+import "C"
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrWorkerPoolClosed is returned by the methods of a WorkerPool after it has
+// been closed.
+var ErrWorkerPoolClosed = errors.New("worker pool is closed")
+
+// WorkerPool runs transactions on a fixed set of goroutines which are locked
+// to their OS threads for their whole lifetime.  Its methods may be called
+// from any goroutine, including goroutines whose thread locking state is not
+// known, which cannot safely call Env.Update or Env.UpdateLocked.
+//
+// Workers do not register their threads with mdbx_thread_register.  Env.Open
+// always sets NoTLS, so reader slots belong to transactions rather than
+// threads and there is nothing to register.  Write transactions are still
+// serialized by the environment, so a pool only helps update throughput in as
+// much as callers no longer have to lock their own threads.
+type WorkerPool struct {
+	env  *Env
+	jobs chan func()
+	wg   sync.WaitGroup
+
+	// mu protects closed and keeps jobs from being closed while a job is
+	// being sent on it.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWorkerPool starts a WorkerPool with n workers for env.  Env must be open
+// and must not be closed before the pool.
+func NewWorkerPool(env *Env, n int) (*WorkerPool, error) {
+	if n <= 0 {
+		return nil, errors.New("worker pool size must be positive")
+	}
+	p := &WorkerPool{
+		env:  env,
+		jobs: make(chan func()),
+	}
+	for i := 0; i < n; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p, nil
+}
+
+// work is the body of a worker goroutine.  The goroutine never unlocks its
+// thread, the runtime terminates the thread when the goroutine exits.
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	runtime.LockOSThread()
+
+	for job := range p.jobs {
+		job()
+	}
+}
+
+// do runs fn on a worker and returns its result.  A panic in fn is recovered
+// on the worker and repeated in the calling goroutine.
+func (p *WorkerPool) do(fn func() error) error {
+	var err error
+	var panicked bool
+	var perr interface{}
+	done := make(chan struct{})
+	job := func() {
+		defer close(done)
+		defer func() {
+			if perr = recover(); perr != nil {
+				panicked = true
+			}
+		}()
+		err = fn()
+	}
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrWorkerPoolClosed
+	}
+	p.jobs <- job
+	p.mu.RUnlock()
+
+	<-done
+	if panicked {
+		panic(perr)
+	}
+	return err
+}
+
+// View runs fn in a readonly transaction on one of the pool's workers.  See
+// Env.View.
+func (p *WorkerPool) View(fn TxnOp) error {
+	return p.do(func() error {
+		return p.env.View(fn)
+	})
+}
+
+// Update runs fn in a write transaction on one of the pool's workers.  See
+// Env.Update.
+//
+// Goroutines created by fn must not use methods on the Txn object that fn is
+// passed.
+func (p *WorkerPool) Update(fn TxnOp) error {
+	return p.do(func() error {
+		return p.env.UpdateLocked(fn)
+	})
+}
+
+// RunTxn runs fn in a transaction created with flags on one of the pool's
+// workers.  See Env.RunTxn.
+func (p *WorkerPool) RunTxn(flags uint, fn TxnOp) error {
+	return p.do(func() error {
+		return p.env.RunTxn(flags, fn)
+	})
+}
+
+// Close waits for running transactions to finish and stops the workers.
+// Calls made after Close return ErrWorkerPoolClosed.
+func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+}
@@ -0,0 +1,105 @@
+package mdbx
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+)
+
+func TestWorkerPool(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	pool, err := NewWorkerPool(env, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var db DBI
+	err = pool.Update(func(txn *Txn) (err error) {
+		db, err = txn.OpenDBI("workers", Create, nil, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 64
+	var wg sync.WaitGroup
+	errc := make(chan error, 2*n)
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			errc <- pool.Update(func(txn *Txn) error {
+				return txn.Put(db, k[:], k[:], 0)
+			})
+		}(i)
+		go func() {
+			defer wg.Done()
+			errc <- pool.View(func(txn *Txn) error {
+				_, err := txn.StatDBI(db)
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	err = pool.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != n {
+			t.Errorf("unexpected entries: %d (!= %d)", stat.Entries, n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	pool.Close()
+	err = pool.View(func(txn *Txn) error { return nil })
+	if err != ErrWorkerPoolClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// Close must be safe to call more than once.
+	pool.Close()
+}
+
+func TestWorkerPool_panic(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	pool, err := NewWorkerPool(env, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	func() {
+		defer func() {
+			if e := recover(); e != "boom" {
+				t.Errorf("unexpected panic: %v", e)
+			}
+		}()
+		_ = pool.Update(func(txn *Txn) error {
+			panic("boom")
+		})
+	}()
+
+	// the worker must survive the panic.
+	err = pool.View(func(txn *Txn) error { return nil })
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,171 @@
+package mdbx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ErrWriterClosed is returned for operations submitted to a Writer after its
+// environment has been closed.
+var ErrWriterClosed = errors.New("writer is closed")
+
+// writerQueueSize is the number of operations which may be queued on a Writer
+// before Submit blocks.
+const writerQueueSize = 64
+
+// Writer applies write transactions submitted from any goroutine on a single
+// goroutine which is locked to its OS thread.  It is the proxy goroutine
+// recommended by the package documentation for goroutines which cannot call
+// Env.Update because their thread locking state is unknown.
+//
+// Operations run one at a time in the order they were submitted, each in its
+// own transaction which is committed if the operation returns nil and aborted
+// otherwise.  An operation which panics is aborted and its future resolves to
+// an error describing the panic.
+type Writer struct {
+	env *Env
+	ops chan *writeOp
+	wg  sync.WaitGroup
+
+	// mu protects closed and keeps ops from being closed while an operation
+	// is being sent on it.
+	mu     sync.RWMutex
+	closed bool
+}
+
+type writeOp struct {
+	ctx context.Context
+	fn  TxnOp
+	f   *WriteFuture
+}
+
+// WriteFuture is the pending result of an operation submitted to a Writer.
+type WriteFuture struct {
+	done    chan struct{}
+	latency CommitLatency
+	err     error
+}
+
+// Done returns a channel which is closed once the operation has finished.
+func (f *WriteFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the operation has finished and returns the commit latency
+// of its transaction along with any error encountered.  The latency is zero if
+// the transaction was not committed.
+func (f *WriteFuture) Wait() (CommitLatency, error) {
+	<-f.done
+	return f.latency, f.err
+}
+
+func (f *WriteFuture) resolve(latency CommitLatency, err error) {
+	f.latency = latency
+	f.err = err
+	close(f.done)
+}
+
+// Writer returns the Writer of env, starting it on first use.  The Writer is
+// stopped when env is closed, after the operations already submitted to it
+// have run.  After env is closed Writer returns a stopped Writer.
+func (env *Env) Writer() *Writer {
+	env.writerOnce.Do(func() {
+		w := &Writer{
+			env: env,
+			ops: make(chan *writeOp, writerQueueSize),
+		}
+		w.wg.Add(1)
+		go w.loop()
+		env.writer = w
+	})
+	return env.writer
+}
+
+func (w *Writer) loop() {
+	defer w.wg.Done()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	for op := range w.ops {
+		op.f.resolve(w.run(op))
+	}
+}
+
+// run executes op in a new write transaction.  The context is checked before
+// the transaction begins and again before it is committed, an operation which
+// is cancelled in between is aborted.
+func (w *Writer) run(op *writeOp) (latency CommitLatency, err error) {
+	if err = op.ctx.Err(); err != nil {
+		return latency, err
+	}
+	txn, err := beginTxn(w.env, nil, 0)
+	if err != nil {
+		return latency, err
+	}
+	defer txn.abort()
+	txn.managed = true
+
+	err = callWriteOp(op.fn, txn)
+	if err != nil {
+		return latency, err
+	}
+	if err = op.ctx.Err(); err != nil {
+		return latency, err
+	}
+	return txn.commit()
+}
+
+// callWriteOp converts a panic in fn into an error, so that the operations
+// queued after fn still run.
+func callWriteOp(fn TxnOp, txn *Txn) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("writer operation panicked: %v", p)
+		}
+	}()
+	return fn(txn)
+}
+
+// Submit queues fn to run in a write transaction and returns immediately
+// unless the queue is full, in which case Submit waits for room or for ctx to
+// be done.  The operation is skipped if ctx is done before it starts and its
+// transaction is aborted if ctx is done by the time fn returns.  In both cases
+// the error returned by the future is ctx.Err().
+//
+// Goroutines created by fn must not use methods on the Txn object that fn is
+// passed.
+func (w *Writer) Submit(ctx context.Context, fn TxnOp) *WriteFuture {
+	f := &WriteFuture{done: make(chan struct{})}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		f.resolve(CommitLatency{}, ErrWriterClosed)
+		return f
+	}
+	select {
+	case w.ops <- &writeOp{ctx: ctx, fn: fn, f: f}:
+	case <-ctx.Done():
+		f.resolve(CommitLatency{}, ctx.Err())
+	}
+	return f
+}
+
+// Update submits fn and waits for it to finish.  See Submit.
+func (w *Writer) Update(ctx context.Context, fn TxnOp) (CommitLatency, error) {
+	return w.Submit(ctx, fn).Wait()
+}
+
+// close stops accepting operations and waits for the queued ones to finish.
+func (w *Writer) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.ops)
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+}
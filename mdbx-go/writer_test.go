@@ -0,0 +1,210 @@
+package mdbx
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestEnv_Writer(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	w := env.Writer()
+	if env.Writer() != w {
+		t.Errorf("Writer returned a different writer")
+	}
+
+	ctx := context.Background()
+	var db DBI
+	_, err := w.Update(ctx, func(txn *Txn) (err error) {
+		db, err = txn.OpenDBI("writer", Create, nil, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 100
+	futures := make([]*WriteFuture, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], uint64(i))
+			futures[i] = w.Submit(ctx, func(txn *Txn) error {
+				return txn.Put(db, k[:], k[:], 0)
+			})
+		}(i)
+	}
+	wg.Wait()
+	for _, f := range futures {
+		lat, err := f.Wait()
+		if err != nil {
+			t.Error(err)
+		}
+		if lat.Whole < 0 {
+			t.Errorf("unexpected latency: %v", lat.Whole)
+		}
+	}
+
+	errFail := errors.New("fail")
+	_, err = w.Update(ctx, func(txn *Txn) error {
+		if err := txn.Put(db, []byte("aborted"), []byte("v"), 0); err != nil {
+			return err
+		}
+		return errFail
+	})
+	if err != errFail {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		stat, err := txn.StatDBI(db)
+		if err != nil {
+			return err
+		}
+		if stat.Entries != n {
+			t.Errorf("unexpected entries: %d (!= %d)", stat.Entries, n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnv_Writer_cancel(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := env.Writer()
+
+	// cancelled before the operation starts.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	_, err = w.Update(ctx, func(txn *Txn) error {
+		called = true
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if called {
+		t.Errorf("cancelled operation was run")
+	}
+
+	// cancelled while the operation runs, the transaction must be aborted.
+	ctx, cancel = context.WithCancel(context.Background())
+	_, err = w.Update(ctx, func(txn *Txn) error {
+		defer cancel()
+		return txn.Put(db, []byte("k"), []byte("v"), 0)
+	})
+	if err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = env.View(func(txn *Txn) error {
+		_, err := txn.Get(db, []byte("k"))
+		if !IsNotFound(err) {
+			t.Errorf("write of a cancelled operation was committed: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnv_Writer_close(t *testing.T) {
+	env := setup(t)
+	path, err := env.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := env.Writer()
+	f := w.Submit(context.Background(), func(txn *Txn) error {
+		return txn.Put(db, []byte("k"), []byte("v"), 0)
+	})
+	if err = env.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// operations queued before Close have run.
+	if _, err = f.Wait(); err != nil {
+		t.Error(err)
+	}
+	_, err = w.Update(context.Background(), func(txn *Txn) error { return nil })
+	if err != ErrWriterClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnv_Writer_afterClose(t *testing.T) {
+	env := setup(t)
+	path, err := env.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	if err = env.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.Writer().Submit(context.Background(), func(txn *Txn) error { return nil }).Wait()
+	if err != ErrWriterClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnv_Writer_panic(t *testing.T) {
+	env := setup(t)
+	defer clean(env, t)
+
+	db, err := openRoot(env, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := env.Writer()
+	ctx := context.Background()
+	fpanic := w.Submit(ctx, func(txn *Txn) error {
+		if err := txn.Put(db, []byte("panic"), []byte("v"), 0); err != nil {
+			return err
+		}
+		panic("boom")
+	})
+	fnext := w.Submit(ctx, func(txn *Txn) error {
+		return txn.Put(db, []byte("next"), []byte("v"), 0)
+	})
+	if _, err := fpanic.Wait(); err == nil {
+		t.Errorf("no error for a panicking operation")
+	}
+	if _, err := fnext.Wait(); err != nil {
+		t.Errorf("operation queued after a panic: %v", err)
+	}
+
+	err = env.View(func(txn *Txn) error {
+		if _, err := txn.Get(db, []byte("panic")); !IsNotFound(err) {
+			t.Errorf("changes of the panicking operation were committed: %v", err)
+		}
+		_, err := txn.Get(db, []byte("next"))
+		return err
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}